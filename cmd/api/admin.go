@@ -0,0 +1,137 @@
+package main
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"net/http"
+	"time"
+)
+
+type IngestResult struct {
+	BaseCode string `json:"base_code"`
+	Fetched  int    `json:"fetched"`
+	Written  int    `json:"written"`
+}
+
+type ProviderStatus struct {
+	Name     string                   `json:"name"`
+	Breakers []provider.BreakerStatus `json:"breakers"`
+}
+
+// Provider status
+//
+//	@Summary		Provider status
+//	@Description	get the configured rate provider and the state of its circuit breakers
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	ProviderStatus
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/providers [get]
+func (app *application) providerStatusHandler(w http.ResponseWriter, r *http.Request) {
+	status := ProviderStatus{
+		Name:     app.provider.Name(),
+		Breakers: []provider.BreakerStatus{},
+	}
+
+	if reporter, ok := app.provider.(provider.BreakerReporter); ok {
+		status.Breakers = append(status.Breakers, reporter.Breakers()...)
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, status); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Ingest latest rates
+//
+//	@Summary		Ingest latest rates
+//	@Description	fetch every rate of a base currency from the provider and upsert them in one transaction
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path	string	true	"Base currency code"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	IngestResult
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Failure		503	{object}	error
+//	@Router			/admin/rates/{base}/latest [post]
+func (app *application) ingestLatestRatesHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+
+	if len(base) != 3 {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	data, err := app.provider.GetLatest(r.Context(), base)
+	if err != nil {
+		app.providerErrorResponse(w, r, err)
+		return
+	}
+
+	rates := make([]store.ExchangeRate, 0, len(data))
+	for i := range data {
+		rates = append(rates, *data[i].ExchangeRate())
+	}
+
+	written, err := app.store.Rates.UpsertMany(r.Context(), rates)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	result := IngestResult{
+		BaseCode: base,
+		Fetched:  len(data),
+		Written:  written,
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Provider usage
+//
+//	@Summary		Provider usage
+//	@Description	get the upstream calls made today and this month against each provider budget
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		provider.UsageReport
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/providers/usage [get]
+func (app *application) providerUsageHandler(w http.ResponseWriter, r *http.Request) {
+	usages, err := app.store.Usage.List(r.Context(), time.Now())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Include providers that were called but have no budget
+	providers := make([]string, 0, len(usages))
+	for _, usage := range usages {
+		providers = append(providers, usage.Provider)
+	}
+
+	reports, err := app.meter.Report(r.Context(), providers...)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, reports); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,355 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+)
+
+type CreateAlertInput struct {
+	BaseCode   string `json:"base_code" validate:"required,len=3"`
+	TargetCode string `json:"target_code" validate:"required,len=3"`
+	// Kind is cross, to fire when the rate crosses Threshold, or change, to
+	// fire when it moves by more than Threshold percent in 24 hours
+	Kind      string          `json:"kind" validate:"required,oneof=cross change"`
+	Threshold decimal.Decimal `json:"threshold" validate:"required,gt=0"`
+}
+
+type UpdateAlertInput struct {
+	Kind      *string          `json:"kind" validate:"omitempty,oneof=cross change"`
+	Threshold *decimal.Decimal `json:"threshold" validate:"omitempty,gt=0"`
+	Active    *bool            `json:"active"`
+}
+
+// List alerts
+//
+//	@Summary		List alerts
+//	@Description	list the rate alerts of a user
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int	true	"user ID"
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		store.Alert
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/alerts [get]
+func (app *application) listAlertsHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(foundUserCtx).(*store.User)
+
+	alerts, err := app.store.Alerts.List(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, alerts); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Create alert
+//
+//	@Summary		Create alert
+//	@Description	email the user when a rate crosses a threshold or moves by more than a percentage in 24 hours
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int					true	"user ID"
+//	@Param			input	body	CreateAlertInput	true	"Alert"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	store.Alert
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/alerts [post]
+func (app *application) createAlertHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(foundUserCtx).(*store.User)
+
+	var input CreateAlertInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	for _, code := range []string{input.BaseCode, input.TargetCode} {
+		if _, err := isSupportedCode(r.Context(), app.store.Currencies, code); err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, fmt.Errorf(errUnsupportedCurrencyFmt, code))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	alert := store.Alert{
+		UserID:     user.ID,
+		BaseCode:   input.BaseCode,
+		TargetCode: input.TargetCode,
+		Kind:       input.Kind,
+		Threshold:  input.Threshold,
+		Active:     true,
+	}
+
+	// A crossing is detected against the rate current when the alert is created
+	rate, err := app.store.Rates.GetByPair(r.Context(), input.BaseCode, input.TargetCode)
+	switch {
+	case err == nil:
+		alert.LastRate = &rate.Rate
+	case !errors.Is(err, store.ErrNotFound):
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.store.Alerts.Create(r.Context(), &alert); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusCreated, alert); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Get alert
+//
+//	@Summary		Get alert
+//	@Description	get a rate alert of a user
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int	true	"user ID"
+//	@Param			alertID	path	int	true	"alert ID"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Alert
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/alerts/{alertID} [get]
+func (app *application) getAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alert := r.Context().Value(alertCtx).(*store.Alert)
+
+	if err := app.jsonResponse(w, http.StatusOK, alert); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Update alert
+//
+//	@Summary		Update alert
+//	@Description	change the condition of a rate alert, or pause and resume it
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int					true	"user ID"
+//	@Param			alertID	path	int					true	"alert ID"
+//	@Param			input	body	UpdateAlertInput	true	"Changed fields"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Alert
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/alerts/{alertID} [patch]
+func (app *application) updateAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alert := r.Context().Value(alertCtx).(*store.Alert)
+
+	var input UpdateAlertInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.Kind != nil {
+		alert.Kind = *input.Kind
+	}
+	if input.Threshold != nil {
+		alert.Threshold = *input.Threshold
+	}
+	if input.Active != nil {
+		alert.Active = *input.Active
+	}
+
+	if err := app.store.Alerts.Update(r.Context(), alert); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, alert); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Delete alert
+//
+//	@Summary		Delete alert
+//	@Description	delete a rate alert of a user
+//	@Tags			alerts
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int	true	"user ID"
+//	@Param			alertID	path	int	true	"alert ID"
+//	@Security		ApiKeyAuth
+//	@Success		204
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/alerts/{alertID} [delete]
+func (app *application) deleteAlertHandler(w http.ResponseWriter, r *http.Request) {
+	alert := r.Context().Value(alertCtx).(*store.Alert)
+
+	if err := app.store.Alerts.Delete(r.Context(), alert.UserID, alert.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// evaluateAlerts checks the alerts watching the pair of a freshly recorded
+// observation and emails the owner of every alert that fires outside its cooldown.
+func (app *application) evaluateAlerts(ctx context.Context, observation store.RateObservation) {
+	alerts, err := app.store.Alerts.ListActive(ctx, observation.BaseCode, observation.TargetCode)
+	if err != nil {
+		app.logAlertError(observation, err)
+		return
+	}
+
+	if len(alerts) == 0 {
+		return
+	}
+
+	// Validated on startup
+	cooldown, _ := time.ParseDuration(app.config.alertConfig.cooldown)
+	now := time.Now().UTC()
+
+	// The rate one window ago, looked up once for every change alert
+	var reference *store.RateObservation
+	referenceLoaded := false
+
+	for _, alert := range alerts {
+		var fired bool
+		var change float64
+
+		switch alert.Kind {
+		case store.AlertCross:
+			fired = alert.Crossed(observation.Rate)
+		case store.AlertChange:
+			if !referenceLoaded {
+				referenceLoaded = true
+				reference, err = app.store.Rates.GetAt(ctx, observation.BaseCode, observation.TargetCode,
+					observation.ObservedAt.Add(-store.AlertChangeWindow))
+				if err != nil && !errors.Is(err, store.ErrNotFound) {
+					app.logAlertError(observation, err)
+				}
+			}
+
+			if reference != nil && !reference.Rate.IsZero() {
+				change = percentChange(reference.Rate, observation.Rate)
+				fired = math.Abs(change) >= alert.Threshold.Float64()
+			}
+		}
+
+		if !fired {
+			if err = app.store.Alerts.Observe(ctx, alert.ID, observation.Rate); err != nil {
+				app.logAlertError(observation, err)
+			}
+			continue
+		}
+
+		notify, err := app.store.Alerts.Trigger(ctx, alert.ID, observation.Rate, now, cooldown)
+		if err != nil {
+			app.logAlertError(observation, err)
+			continue
+		}
+
+		if notify {
+			app.sendAlert(ctx, alert, observation, change)
+		}
+	}
+}
+
+// percentChange is the move from a non-zero rate to another, in percent
+func percentChange(from, to decimal.Decimal) float64 {
+	return to.Sub(from).Div(from, decimal.DivisionScale).Float64() * 100
+}
+
+func (app *application) sendAlert(ctx context.Context, alert store.Alert, observation store.RateObservation, change float64) {
+	user, err := app.store.Users.GetByID(ctx, alert.UserID)
+	if err != nil {
+		app.logAlertError(observation, err)
+		return
+	}
+
+	data := map[string]any{
+		"username":  user.Username,
+		"base":      observation.BaseCode,
+		"target":    observation.TargetCode,
+		"rate":      observation.Rate,
+		"cross":     alert.Kind == store.AlertCross,
+		"threshold": alert.Threshold,
+		"change":    change,
+		"at":        observation.ObservedAt.Format(time.RFC1123),
+	}
+
+	if err = app.mailer.Send(user.Email, "rate_alert.tmpl", data); err != nil {
+		app.logAlertError(observation, err)
+		return
+	}
+
+	app.logger.LogAttrs(ctx,
+		slog.LevelInfo,
+		"Rate alert sent",
+		slog.Int64("alert", alert.ID),
+		slog.Int64("user", alert.UserID),
+		slog.String("pair", observation.BaseCode+"/"+observation.TargetCode),
+	)
+}
+
+func (app *application) logAlertError(observation store.RateObservation, err error) {
+	app.logger.LogAttrs(context.Background(),
+		slog.LevelError,
+		"Rate alert evaluation failed",
+		slog.String("pair", observation.BaseCode+"/"+observation.TargetCode),
+		slog.String("error", err.Error()),
+	)
+}
@@ -2,27 +2,50 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/minhnghia2k3/exchanger/internal/mail"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
 	"github.com/minhnghia2k3/exchanger/internal/store"
+	"github.com/minhnghia2k3/exchanger/internal/webhook"
 	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 )
 
 type application struct {
-	config config
-	store  *store.Storage
-	mailer *mail.Mailer
-	logger *slog.Logger
+	config   config
+	store    *store.Storage
+	mailer   *mail.Mailer
+	provider provider.RateProvider
+	meter    *provider.Meter
+	broker   *rateBroker
+	graph    *rateGraphCache
+	tasks    *taskQueue
+	// deliveries runs webhook deliveries apart from tasks, so that retries
+	// against slow receivers do not hold up alert evaluation
+	deliveries *taskQueue
+	webhooks   *webhook.Sender
+	logger     *slog.Logger
 }
 
 type config struct {
-	port       int
-	env        string
-	dbConfig   dbConfig
-	mailConfig mailConfig
-	jwtConfig  jwtConfig
+	port            int
+	env             string
+	dbConfig        dbConfig
+	mailConfig      mailConfig
+	jwtConfig       jwtConfig
+	providerConfig  providerConfig
+	refresherConfig refresherConfig
+	staleConfig     staleConfig
+	alertConfig     alertConfig
+	webhookConfig   webhookConfig
+	roundingConfig  roundingConfig
+	taskConfig      taskConfig
 }
 
 type jwtConfig struct {
@@ -39,6 +62,53 @@ type dbConfig struct {
 	maxIdleTime string
 }
 
+type providerConfig struct {
+	name             string
+	apiKey           string
+	baseURL          string
+	strategy         string
+	tolerance        float64
+	breakerThreshold int
+	breakerCooldown  string
+	cache            bool
+	budgets          string
+}
+
+type refresherConfig struct {
+	interval    string
+	batchSize   int
+	concurrency int
+}
+
+type staleConfig struct {
+	policy  string
+	refresh bool
+}
+
+type alertConfig struct {
+	cooldown string
+}
+
+type webhookConfig struct {
+	allowHTTP bool
+	// allowPrivate accepts webhook URLs on loopback and private networks, for local development
+	allowPrivate bool
+	maxAttempts  int
+	backoff      string
+	disableAfter int
+	workers      int
+	queueSize    int
+}
+
+type roundingConfig struct {
+	mode string
+}
+
+type taskConfig struct {
+	workers   int
+	queueSize int
+}
+
 type mailConfig struct {
 	sender   string
 	host     string
@@ -55,6 +125,28 @@ func (app *application) serve() error {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  time.Minute,
 	}
+	srv.RegisterOnShutdown(app.broker.close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Background workers stop when ctx is cancelled
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		app.runRefresher(ctx)
+	}()
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		shutdownErr <- srv.Shutdown(shutdownCtx)
+	}()
 
 	app.logger.LogAttrs(context.Background(),
 		slog.LevelInfo,
@@ -62,5 +154,26 @@ func (app *application) serve() error {
 		slog.String("application", "exchanger"),
 		slog.Int("port", app.config.port),
 	)
-	return srv.ListenAndServe()
+
+	err := srv.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		stop()
+		wg.Wait()
+		return err
+	}
+
+	if err = <-shutdownErr; err != nil {
+		return err
+	}
+	wg.Wait()
+
+	// Rate changes made until now still notify their alerts and webhooks
+	drainCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	app.tasks.shutdown(drainCtx)
+	// Drained after tasks, which queue the deliveries
+	app.deliveries.shutdown(drainCtx)
+
+	app.logger.LogAttrs(context.Background(), slog.LevelInfo, "Server stopped")
+	return nil
 }
@@ -0,0 +1,454 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	maxImportBytes = 10 << 20
+	maxImportRows  = 10000
+	// importValidity is how long an imported rate lasts before the refresher fetches the pair again
+	importValidity = 24 * time.Hour
+	// exportFlushRows is how many rows are buffered before they are sent to the client
+	exportFlushRows = 500
+)
+
+var (
+	errImportFormat     = errors.New("content type must be text/csv or application/x-ndjson")
+	errImportEmpty      = errors.New("import has no rows")
+	errImportTooLarge   = fmt.Errorf("import must have at most %d rows", maxImportRows)
+	errImportColumns    = errors.New("header must have base, target and rate columns")
+	errImportSamePair   = errors.New("base and target must differ")
+	errImportRate       = errors.New("rate must be a positive number")
+	errImportAsOf       = errors.New("as_of must be an RFC 3339 timestamp or a YYYY-MM-DD date")
+	errImportFutureAsOf = errors.New("as_of must not be in the future")
+)
+
+// importColumns is the column order of CSV imports without a header row
+var importColumns = []string{"base", "target", "rate", "as_of"}
+
+type ImportError struct {
+	// Row is the line of the row in the uploaded file
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+type ImportResult struct {
+	Rows int `json:"rows"`
+	// Imported is the number of rows recorded, zero when any row is rejected
+	Imported int `json:"imported"`
+	// Current is the number of pairs whose current rate changed, older rows only fill in history
+	Current int           `json:"current"`
+	Errors  []ImportError `json:"errors"`
+}
+
+// importRow is one row of an import before validation
+type importRow struct {
+	line   int
+	base   string
+	target string
+	rate   string
+	asOf   string
+	// err rejects a row that could not be decoded
+	err error
+}
+
+// jsonImportRow is one line of a JSON lines import
+type jsonImportRow struct {
+	Base   string      `json:"base"`
+	Target string      `json:"target"`
+	Rate   json.Number `json:"rate"`
+	AsOf   string      `json:"as_of"`
+}
+
+// Import exchange rates
+//
+//	@Summary		Import exchange rates
+//	@Description	load rates from CSV (base,target,rate,as_of with an optional header) or JSON lines in one transaction.
+//	@Description	Nothing is written when any row is invalid, the response lists the rejected rows.
+//	@Tags			Admin
+//	@Accept			text/csv,application/x-ndjson
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	ImportResult
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		422	{object}	ImportResult
+//	@Failure		500	{object}	error
+//	@Router			/rates/import [post]
+func (app *application) importRatesHandler(w http.ResponseWriter, r *http.Request) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var parse func(io.Reader) ([]importRow, error)
+	switch mediaType {
+	case "text/csv":
+		parse = parseCSVImport
+	case "application/x-ndjson", "application/jsonl":
+		parse = parseJSONLinesImport
+	default:
+		app.badRequestResponse(w, r, errImportFormat)
+		return
+	}
+
+	rows, err := parse(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if len(rows) == 0 {
+		app.badRequestResponse(w, r, errImportEmpty)
+		return
+	}
+
+	rates, result, err := app.validateImport(r.Context(), rows, time.Now().UTC())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if len(result.Errors) > 0 {
+		if err = app.jsonResponse(w, http.StatusUnprocessableEntity, result); err != nil {
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	result.Current, err = app.store.Rates.Import(r.Context(), rates)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+	result.Imported = len(rates)
+
+	if err = app.jsonResponse(w, http.StatusOK, result); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// validateImport turns rows into rates, reporting every invalid row. Currency
+// codes are looked up once per import.
+func (app *application) validateImport(ctx context.Context, rows []importRow,
+	now time.Time) ([]store.ExchangeRate, ImportResult, error) {
+	result := ImportResult{Rows: len(rows), Errors: []ImportError{}}
+	rates := make([]store.ExchangeRate, 0, len(rows))
+	supported := make(map[string]bool)
+
+	for _, row := range rows {
+		reject := func(err error) {
+			result.Errors = append(result.Errors, ImportError{Row: row.line, Error: err.Error()})
+		}
+
+		if row.err != nil {
+			reject(row.err)
+			continue
+		}
+
+		base := strings.ToUpper(strings.TrimSpace(row.base))
+		target := strings.ToUpper(strings.TrimSpace(row.target))
+
+		if !validCurrencyCode(base, target) {
+			reject(errInvalidCurrencyCode)
+			continue
+		}
+
+		if base == target {
+			reject(errImportSamePair)
+			continue
+		}
+
+		unsupported := ""
+		for _, code := range []string{base, target} {
+			ok, seen := supported[code]
+			if !seen {
+				var err error
+				ok, err = isSupportedCode(ctx, app.store.Currencies, code)
+				if err != nil && !errors.Is(err, store.ErrNotFound) {
+					return nil, result, err
+				}
+				supported[code] = ok
+			}
+
+			if !ok {
+				unsupported = code
+				break
+			}
+		}
+
+		if unsupported != "" {
+			reject(fmt.Errorf(errUnsupportedCurrencyFmt, unsupported))
+			continue
+		}
+
+		rate, err := decimal.Parse(strings.TrimSpace(row.rate))
+		if err != nil || rate.Sign() <= 0 {
+			reject(errImportRate)
+			continue
+		}
+
+		asOf, err := parseAsOf(row.asOf, now)
+		if err != nil {
+			reject(err)
+			continue
+		}
+
+		rates = append(rates, store.ExchangeRate{
+			BaseCode:   base,
+			TargetCode: target,
+			Rate:       rate,
+			LastUpdate: asOf,
+			NextUpdate: asOf.Add(importValidity),
+		})
+	}
+
+	return rates, result, nil
+}
+
+// parseAsOf reads an RFC 3339 timestamp, converted to UTC, or a date at midnight UTC. Empty means now.
+func parseAsOf(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return now, nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		asOf, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			return time.Time{}, errImportAsOf
+		}
+	}
+
+	if asOf.After(now) {
+		return time.Time{}, errImportFutureAsOf
+	}
+
+	return asOf.UTC(), nil
+}
+
+// parseCSVImport reads base,target,rate,as_of rows. A first row starting with
+// "base" is a header naming the columns, which may then come in any order.
+func parseCSVImport(body io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := make(map[string]int, len(importColumns))
+	for i, name := range importColumns {
+		columns[name] = i
+	}
+
+	var rows []importRow
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "base") {
+			columns = make(map[string]int, len(record))
+			for i, name := range record {
+				columns[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+
+			for _, name := range importColumns[:3] {
+				if _, ok := columns[name]; !ok {
+					return nil, errImportColumns
+				}
+			}
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, errImportTooLarge
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return record[i]
+		}
+
+		rows = append(rows, importRow{
+			line:   line,
+			base:   field("base"),
+			target: field("target"),
+			rate:   field("rate"),
+			asOf:   field("as_of"),
+		})
+	}
+
+	return rows, nil
+}
+
+// parseJSONLinesImport reads one {"base","target","rate","as_of"} object per line, blank lines are skipped.
+// Lines that are not valid objects are kept to be reported with the other rejected rows.
+func parseJSONLinesImport(body io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		if len(rows) == maxImportRows {
+			return nil, errImportTooLarge
+		}
+
+		var record jsonImportRow
+		err := json.Unmarshal([]byte(text), &record)
+
+		rows = append(rows, importRow{
+			line:   line,
+			base:   record.Base,
+			target: record.Target,
+			rate:   record.Rate.String(),
+			asOf:   record.AsOf,
+			err:    err,
+		})
+	}
+
+	return rows, scanner.Err()
+}
+
+// Export exchange rates
+//
+//	@Summary		Export exchange rates
+//	@Description	stream the current rates as CSV, or every observation between from and to when either is set
+//	@Tags			Admin
+//	@Produce		text/csv
+//	@Param			base	query	string	false	"Base currency code"
+//	@Param			target	query	string	false	"Target currency code"
+//	@Param			from	query	string	false	"Start time (RFC 3339)"
+//	@Param			to		query	string	false	"End time (RFC 3339)"
+//	@Security		ApiKeyAuth
+//	@Success		200	{string}	string	"base,target,rate,bid,ask,as_of,source"
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/export [get]
+func (app *application) exportRatesHandler(w http.ResponseWriter, r *http.Request) {
+	base := strings.ToUpper(readString(r, "base", ""))
+	target := strings.ToUpper(readString(r, "target", ""))
+
+	if (base != "" && len(base) != 3) || (target != "" && len(target) != 3) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	from, err := readTime(r, "from")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readTime(r, "to")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		app.badRequestResponse(w, r, errInvalidTimeRange)
+		return
+	}
+
+	// Large exports outlive the server write timeout
+	rc := http.NewResponseController(w)
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="rates.csv"`)
+
+	writer := csv.NewWriter(w)
+	rows, flushed := 0, false
+
+	write := func(base, target string, rate, bid, ask decimal.Decimal, asOf time.Time, source string) error {
+		err := writer.Write([]string{
+			base,
+			target,
+			rate.String(),
+			bid.String(),
+			ask.String(),
+			asOf.UTC().Format(time.RFC3339),
+			source,
+		})
+		if err != nil {
+			return err
+		}
+
+		rows++
+		if rows%exportFlushRows == 0 {
+			writer.Flush()
+			flushed = true
+			return writer.Error()
+		}
+
+		return nil
+	}
+
+	_ = writer.Write([]string{"base", "target", "rate", "bid", "ask", "as_of", "source"})
+
+	if from.IsZero() && to.IsZero() {
+		err = app.store.Rates.Walk(r.Context(), base, target, func(rate *store.ExchangeRate) error {
+			return write(rate.BaseCode, rate.TargetCode, rate.Rate, rate.Bid, rate.Ask, rate.LastUpdate, rate.Source)
+		})
+	} else {
+		err = app.store.Rates.WalkHistory(r.Context(), base, target, from, to, func(o *store.RateObservation) error {
+			return write(o.BaseCode, o.TargetCode, o.Rate, o.Bid, o.Ask, o.ObservedAt, o.Source)
+		})
+	}
+
+	if err != nil {
+		// Once rows went out the status is sent, the client sees a truncated file
+		if !flushed {
+			app.internalServerError(w, r, err)
+			return
+		}
+
+		app.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"Rate export interrupted",
+			slog.Int("rows", rows),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	writer.Flush()
+	if err = writer.Error(); err != nil {
+		app.logger.LogAttrs(r.Context(),
+			slog.LevelError,
+			"Rate export interrupted",
+			slog.Int("rows", rows),
+			slog.String("error", err.Error()),
+		)
+	}
+}
@@ -10,12 +10,15 @@ type AddCurrencyInput struct {
 	Code      string  `json:"code" validate:"required,len=3"`
 	Name      string  `json:"name" validate:"required,len=50"`
 	SymbolUrl *string `json:"symbol_url" validate:"omitempty,url"`
+	// MinorUnits defaults to the ISO 4217 value of the code
+	MinorUnits *int `json:"minor_units" validate:"omitempty,min=0,max=4"`
 }
 
 type UpdateCurrencyInput struct {
-	Code      string  `json:"code" validate:"omitempty,len=3"`
-	Name      string  `json:"name" validate:"omitempty,len=50"`
-	SymbolUrl *string `json:"symbol_url" validate:"omitempty,url"`
+	Code       string  `json:"code" validate:"omitempty,len=3"`
+	Name       string  `json:"name" validate:"omitempty,len=50"`
+	SymbolUrl  *string `json:"symbol_url" validate:"omitempty,url"`
+	MinorUnits *int    `json:"minor_units" validate:"omitempty,min=0,max=4"`
 }
 
 // List currencies
@@ -87,9 +90,14 @@ func (app *application) addCurrencyHandler(w http.ResponseWriter, r *http.Reques
 	}
 
 	currency := store.Currency{
-		Code:      input.Code,
-		Name:      input.Name,
-		SymbolUrl: input.SymbolUrl,
+		Code:       input.Code,
+		Name:       input.Name,
+		SymbolUrl:  input.SymbolUrl,
+		MinorUnits: store.DefaultMinorUnits(input.Code),
+	}
+
+	if input.MinorUnits != nil {
+		currency.MinorUnits = *input.MinorUnits
 	}
 
 	if err := app.store.Currencies.Insert(r.Context(), &currency); err != nil {
@@ -151,6 +159,19 @@ func (app *application) updateCurrencyHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if input.Code != "" {
+		currency.Code = input.Code
+	}
+	if input.Name != "" {
+		currency.Name = input.Name
+	}
+	if input.SymbolUrl != nil {
+		currency.SymbolUrl = input.SymbolUrl
+	}
+	if input.MinorUnits != nil {
+		currency.MinorUnits = *input.MinorUnits
+	}
+
 	if err := app.store.Currencies.Update(r.Context(), currency.ID, currency); err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
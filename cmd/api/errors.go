@@ -85,3 +85,27 @@ func (app *application) forbiddenResponse(w http.ResponseWriter, r *http.Request
 	msg := "You are not allowed to access this route"
 	writeJSONError(w, http.StatusForbidden, msg)
 }
+
+func (app *application) serviceUnavailableResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.LogAttrs(context.Background(),
+		slog.LevelWarn,
+		"Service unavailable:",
+		slog.String("URL", r.URL.String()),
+		slog.String("method", r.Method),
+		slog.String("error", err.Error()),
+	)
+	msg := "rate provider is temporarily unavailable"
+	writeJSONError(w, http.StatusServiceUnavailable, msg)
+}
+
+func (app *application) staleRateResponse(w http.ResponseWriter, r *http.Request, err error) {
+	app.logger.LogAttrs(context.Background(),
+		slog.LevelWarn,
+		"Stale rate:",
+		slog.String("URL", r.URL.String()),
+		slog.String("method", r.Method),
+		slog.String("error", err.Error()),
+	)
+
+	writeJSONError(w, http.StatusServiceUnavailable, err.Error())
+}
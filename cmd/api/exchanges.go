@@ -1,34 +1,73 @@
 package main
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
-	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/rategraph"
 	"github.com/minhnghia2k3/exchanger/internal/store"
 )
 
 var (
 	ErrInvalidAmount = errors.New("invalid converted amount")
+	ErrFutureTime    = errors.New("at must not be in the future")
+	errInvalidSide   = errors.New("side must be buy, sell or mid")
 )
 
+type ExchangeResult struct {
+	BaseCode   string          `json:"base_code"`
+	TargetCode string          `json:"target_code"`
+	Amount     decimal.Decimal `json:"amount"`
+	// Side is buy or sell for the customer buying or selling the base currency, mid for the interbank rate
+	Side string `json:"side"`
+	// Rate is the ask when buying, the bid when selling and MidRate otherwise
+	Rate    decimal.Decimal `json:"rate"`
+	MidRate decimal.Decimal `json:"mid_rate"`
+	// SpreadBps is how far Rate is from MidRate, in basis points
+	SpreadBps float64 `json:"spread_bps"`
+	// UnroundedResult is the exact product of Amount and Rate
+	UnroundedResult decimal.Decimal `json:"unrounded_result"`
+	// Result is UnroundedResult rounded to the minor units of the target currency
+	Result     decimal.Decimal      `json:"result"`
+	MinorUnits int                  `json:"minor_units"`
+	Rounding   decimal.RoundingMode `json:"rounding"`
+	// RateTime is when the applied rate was observed, the oldest leg for derived rates
+	RateTime time.Time `json:"rate_time"`
+	// Provenance tells whether the rate is stored, inverted or chained through other pairs
+	Provenance rategraph.Provenance `json:"provenance"`
+	// Path lists the currencies the conversion went through, base first
+	Path []string `json:"path"`
+	Staleness
+}
+
 // Exchange rate handler
 //
 //	@Summary		exchange rate
-//	@Description	exchange rate
+//	@Description	convert an amount at the current rate, or at the rate in effect at a given time.
+//	@Description	Pairs that are not stored are derived from their inverse or through other stored pairs,
+//	@Description	for past conversions through the pairs as they were at that time.
 //	@Tags			Exchanges
 //	@Accept			json
 //	@Produce		json
 //	@Param			base	path	string	true	"Base currency code"
 //	@Param			target	path	string	true	"Target currency code"
 //	@Param			amount	path	string	true	"Amount to convert"
-//	@Success		200	{object}	float64
+//	@Param			at		query	string	false	"Convert at the rate in effect at this time (RFC 3339)"
+//	@Param			side	query	string	false	"Customer side on the base currency"	Enums(buy, sell, mid)
+//	@Param			rounding	query	string	false	"Rounding of the result, defaults to the configured mode"	Enums(half_up, half_even, floor, ceiling)
+//	@Success		200	{object}	ExchangeResult
 //	@Failure		400	{object}	error
 //	@Failure		401	{object}	error
 //	@Failure		404	{object}	error
 //	@Failure		500	{object}	error
-//	@Router			/exchanges/pair/{base}/{target}/{amount} [post]
+//	@Failure		503	{object}	error	"the rate is stale and the deployment rejects stale rates"
+//	@Router			/exchanges/pair/{base}/{target}/{amount} [get]
 func (app *application) exchangePairHandler(w http.ResponseWriter, r *http.Request) {
 	// Get base
 	base := chi.URLParam(r, "base")
@@ -38,13 +77,13 @@ func (app *application) exchangePairHandler(w http.ResponseWriter, r *http.Reque
 
 	// Get amount
 	amountParam := chi.URLParam(r, "amount")
-	amount, err := strconv.ParseFloat(amountParam, 64)
+	amount, err := decimal.Parse(amountParam)
 	if err != nil {
 		app.badRequestResponse(w, r, err)
 		return
 	}
 
-	if amount < 1 {
+	if amount.Cmp(decimal.One) < 0 {
 		app.badRequestResponse(w, r, ErrInvalidAmount)
 		return
 	}
@@ -55,30 +94,140 @@ func (app *application) exchangePairHandler(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Get rates by pair
-	rates, err := app.store.Rates.GetByPair(r.Context(), base, target)
+	at, err := readTime(r, "at")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if at.After(time.Now()) {
+		app.badRequestResponse(w, r, ErrFutureTime)
+		return
+	}
+
+	side := readString(r, "side", store.SideMid)
+	if side != store.SideMid && side != store.SideBuy && side != store.SideSell {
+		app.badRequestResponse(w, r, errInvalidSide)
+		return
+	}
+
+	rounding, err := decimal.ParseRoundingMode(readString(r, "rounding", app.config.roundingConfig.mode))
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	// The result is rounded to the minor units of the target currency
+	currency, err := app.store.Currencies.GetByCode(r.Context(), target)
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
-			// If there is no rates in database => fetch and store
-			app.notFoundResponse(w, r, err)
+			app.notFoundResponse(w, r, fmt.Errorf(errUnsupportedCurrencyFmt, target))
 		default:
 			app.internalServerError(w, r, err)
 		}
 		return
 	}
 
-	// Get rates and calculate the target result.
-	result := amount * rates.Rate
+	exchange := ExchangeResult{
+		BaseCode:   base,
+		TargetCode: target,
+		Amount:     amount,
+		Side:       side,
+		MinorUnits: currency.MinorUnits,
+		Rounding:   rounding,
+	}
+
+	var mid, bid, ask decimal.Decimal
+
+	// Get rates by pair, from history for point-in-time conversions
+	if at.IsZero() {
+		path, err := app.resolveRate(r.Context(), base, target)
+		if err != nil {
+			switch {
+			case errors.Is(err, rategraph.ErrNoPath):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		// Try to bring stale pairs up to date before converting
+		if app.config.staleConfig.refresh && path.NextUpdate.Before(time.Now()) {
+			if err = app.refreshPath(r.Context(), path); err != nil {
+				// Fall back to the stale rate, the policy decides whether it is served
+				app.logger.LogAttrs(r.Context(), slog.LevelWarn, "On-demand refresh failed",
+					slog.String("pair", base+"/"+target),
+					slog.String("error", err.Error()),
+				)
+			} else if path, err = app.resolveRate(r.Context(), base, target); err != nil {
+				app.internalServerError(w, r, err)
+				return
+			}
+		}
+
+		mid, bid, ask = path.Rate, path.Bid, path.Ask
+		exchange.RateTime = path.LastUpdate
+		exchange.Provenance = path.Provenance()
+		exchange.Path = path.Codes()
+		exchange.Staleness = staleness(path.LastUpdate, path.NextUpdate, time.Now())
+
+		if err = app.applyStalePolicy(w, exchange.Staleness); err != nil {
+			app.staleRateResponse(w, r, err)
+			return
+		}
+	} else {
+		path, err := app.resolveRateAt(r.Context(), base, target, at)
+		if err != nil {
+			switch {
+			case errors.Is(err, rategraph.ErrNoPath):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		mid, bid, ask = path.Rate, path.Bid, path.Ask
+		exchange.RateTime = path.LastUpdate
+		exchange.Provenance = path.Provenance()
+		exchange.Path = path.Codes()
+		// A past rate is never stale, its age is measured from the requested time
+		exchange.AgeSeconds = int64(at.Sub(path.LastUpdate).Seconds())
+	}
+
+	// Quote the side's price at the stored scale, derived rates carry more
+	// digits, and calculate the target result from the quoted rate.
+	exchange.MidRate = mid.Round(decimal.Scale)
+	switch side {
+	case store.SideBuy:
+		exchange.Rate = ask.Round(decimal.Scale)
+	case store.SideSell:
+		exchange.Rate = bid.Round(decimal.Scale)
+	default:
+		exchange.Rate = exchange.MidRate
+	}
+
+	if !exchange.MidRate.IsZero() {
+		spread := exchange.Rate.Div(exchange.MidRate, decimal.DivisionScale).Sub(decimal.One)
+		exchange.SpreadBps = spread.Abs().Float64() * 10000
+	}
+
+	exchange.UnroundedResult = amount.Mul(exchange.Rate)
+	exchange.Result = exchange.UnroundedResult.RoundWith(int32(currency.MinorUnits), rounding)
 
 	// Store data to transaction history
 	transaction := &store.Transaction{
 		UserID:          nil,
-		BaseCode:        rates.BaseCode,
-		TargetCode:      rates.TargetCode,
+		BaseCode:        exchange.BaseCode,
+		TargetCode:      exchange.TargetCode,
 		ConvertedAmount: amount,
-		ConvertedRate:   rates.Rate,
-		Result:          result,
+		ConvertedRate:   exchange.Rate,
+		Side:            exchange.Side,
+		MidRate:         exchange.MidRate,
+		SpreadBps:       exchange.SpreadBps,
+		Result:          exchange.Result,
 	}
 
 	err = app.store.Transactions.Save(r.Context(), transaction)
@@ -88,7 +237,74 @@ func (app *application) exchangePairHandler(w http.ResponseWriter, r *http.Reque
 	}
 
 	// Return result
-	if err = app.jsonResponse(w, http.StatusOK, result); err != nil {
+	if err = app.jsonResponse(w, http.StatusOK, exchange); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
+
+// resolveRate uses the stored pair when there is one, otherwise derives the
+// rate from the inverse pair or the shortest chain of stored pairs.
+func (app *application) resolveRate(ctx context.Context, base, target string) (*rategraph.Path, error) {
+	rate, err := app.store.Rates.GetByPair(ctx, base, target)
+	if err == nil {
+		return rategraph.PathOf(rategraph.Hop{
+			From:       rate.BaseCode,
+			To:         rate.TargetCode,
+			Rate:       rate.Rate,
+			Bid:        rate.Bid,
+			Ask:        rate.Ask,
+			LastUpdate: rate.LastUpdate,
+			NextUpdate: rate.NextUpdate,
+		}), nil
+	}
+
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	graph, err := app.graph.get(ctx, app.store.Rates)
+	if err != nil {
+		return nil, err
+	}
+
+	return graph.Find(base, target)
+}
+
+// resolveRateAt is resolveRate over the observations in effect at the given
+// instant, chains are walked through the pairs as they were then
+func (app *application) resolveRateAt(ctx context.Context, base, target string, at time.Time) (*rategraph.Path, error) {
+	observation, err := app.store.Rates.GetAt(ctx, base, target, at)
+	if err == nil {
+		return rategraph.PathOf(rategraph.Hop{
+			From:       observation.BaseCode,
+			To:         observation.TargetCode,
+			Rate:       observation.Rate,
+			Bid:        observation.Bid,
+			Ask:        observation.Ask,
+			LastUpdate: observation.ObservedAt,
+		}), nil
+	}
+
+	if !errors.Is(err, store.ErrNotFound) {
+		return nil, err
+	}
+
+	observations, err := app.store.Rates.AllAt(ctx, at)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]store.ExchangeRate, 0, len(observations))
+	for _, o := range observations {
+		rates = append(rates, store.ExchangeRate{
+			BaseCode:   o.BaseCode,
+			TargetCode: o.TargetCode,
+			Rate:       o.Rate,
+			Bid:        o.Bid,
+			Ask:        o.Ask,
+			LastUpdate: o.ObservedAt,
+		})
+	}
+
+	return rategraph.New(rates).Find(base, target)
+}
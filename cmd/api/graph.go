@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/rategraph"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+)
+
+// rateGraphTTL bounds how long a graph is reused, rates written by other
+// processes such as cmd/ingest do not invalidate it
+const rateGraphTTL = time.Minute
+
+// rateGraphCache keeps the graph of the stored pairs between rate changes so
+// that derived conversions do not load every pair on each request
+type rateGraphCache struct {
+	mu      sync.Mutex
+	graph   *rategraph.Graph
+	builtAt time.Time
+}
+
+// invalidate drops the graph, the next get rebuilds it from the stored pairs
+func (c *rateGraphCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.graph = nil
+}
+
+// get returns the cached graph, rebuilding it from rates when it is missing or expired
+func (c *rateGraphCache) get(ctx context.Context, rates store.IExchangeRates) (*rategraph.Graph, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.graph != nil && time.Since(c.builtAt) < rateGraphTTL {
+		return c.graph, nil
+	}
+
+	all, err := rates.All(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.graph = rategraph.New(all)
+	c.builtAt = time.Now()
+
+	return c.graph, nil
+}
@@ -3,11 +3,10 @@ package main
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 func readInt(r *http.Request, key string, fallback int) int {
@@ -35,31 +34,25 @@ func readString(r *http.Request, key string, fallback string) string {
 	return val
 }
 
-func SHA256Hash(text string) string {
-	h := sha256.Sum256([]byte(text))
-
-	return hex.EncodeToString(h[:])
-}
+// readTime parses an RFC 3339 query parameter into UTC, the zone timestamps are
+// stored in. A missing parameter yields the zero time.
+func readTime(r *http.Request, key string) (time.Time, error) {
+	val := r.URL.Query().Get(key)
 
-func GetAPI(url string, data any) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
+	if val == "" {
+		return time.Time{}, nil
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	defer resp.Body.Close()
-	if resp.StatusCode > 299 {
-		return fmt.Errorf("%w, response failed with status code: %d and\nbody: %s\n", errUnsupportedCurrencyCode, resp.StatusCode, body)
-	}
+	t, err := time.Parse(time.RFC3339, val)
 	if err != nil {
-		return err
+		return time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", key)
 	}
 
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return err
-	}
+	return t.UTC(), nil
+}
 
-	return nil
+func SHA256Hash(text string) string {
+	h := sha256.Sum256([]byte(text))
+
+	return hex.EncodeToString(h[:])
 }
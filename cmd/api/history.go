@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"net/http"
+)
+
+var (
+	errInvalidTimeRange = errors.New("from must be before to")
+	errInvalidLimit     = errors.New("limit must be between 1 and 1000")
+)
+
+// Exchange rate history
+//
+//	@Summary		Exchange rate history
+//	@Description	list every recorded rate of a pair, newest first by default
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base		path	string	true	"Base currency code"
+//	@Param			target		path	string	true	"Target currency code"
+//	@Param			from		query	string	false	"Start time (RFC 3339)"
+//	@Param			to			query	string	false	"End time (RFC 3339)"
+//	@Param			page		query	int		false	"Current page"
+//	@Param			page_size	query	int		false	"Page size"
+//	@Param			sort		query	string	false	"Sort"
+//	@Success		200
+//	@Failure		400	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/{base}/{target}/history [get]
+func (app *application) rateHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	from, err := readTime(r, "from")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readTime(r, "to")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		app.badRequestResponse(w, r, errInvalidTimeRange)
+		return
+	}
+
+	input := store.Filter{
+		Page:         readInt(r, "page", 1),
+		PageSize:     readInt(r, "page_size", 10),
+		Sort:         readString(r, "sort", "-observed_at"),
+		SortSafeList: []string{"observed_at", "rate"},
+	}
+
+	if err = Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	list, metadata, err := app.store.Rates.History(r.Context(), base, target, from, to, input)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = writeJSON(w, http.StatusOK, envelop{"metadata": metadata, "data": list}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Exchange rate candles
+//
+//	@Summary		Exchange rate candles
+//	@Description	open, high, low and close of a pair per interval, computed from the rate history
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base		path	string	true	"Base currency code"
+//	@Param			target		path	string	true	"Target currency code"
+//	@Param			interval	query	string	false	"Candle interval"	Enums(1h, 1d, 1w)
+//	@Param			from		query	string	false	"Start time (RFC 3339)"
+//	@Param			to			query	string	false	"End time (RFC 3339)"
+//	@Param			limit		query	int		false	"Maximum number of candles, latest first"
+//	@Success		200	{array}		store.Candle
+//	@Failure		400	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/{base}/{target}/candles [get]
+func (app *application) rateCandlesHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	from, err := readTime(r, "from")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readTime(r, "to")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		app.badRequestResponse(w, r, errInvalidTimeRange)
+		return
+	}
+
+	limit := readInt(r, "limit", 500)
+	if limit < 1 || limit > 1000 {
+		app.badRequestResponse(w, r, errInvalidLimit)
+		return
+	}
+
+	interval := readString(r, "interval", "1d")
+
+	candles, err := app.store.Rates.Candles(r.Context(), base, target, interval, from, to, limit)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrInvalidInterval):
+			app.badRequestResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if candles == nil {
+		candles = []store.Candle{}
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, candles); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
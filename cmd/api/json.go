@@ -3,7 +3,9 @@ package main
 import (
 	"encoding/json"
 	"github.com/go-playground/validator/v10"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
 	"net/http"
+	"reflect"
 )
 
 type envelop map[string]any
@@ -12,6 +14,11 @@ var Validate *validator.Validate
 
 func init() {
 	Validate = validator.New(validator.WithRequiredStructEnabled())
+
+	// Lets numeric tags such as gt=0 apply to decimals
+	Validate.RegisterCustomTypeFunc(func(field reflect.Value) any {
+		return field.Interface().(decimal.Decimal).Float64()
+	}, decimal.Decimal{})
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) error {
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
 	"github.com/joho/godotenv"
 	"github.com/minhnghia2k3/exchanger/internal/database"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
 	"github.com/minhnghia2k3/exchanger/internal/env"
 	"github.com/minhnghia2k3/exchanger/internal/mail"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
 	"github.com/minhnghia2k3/exchanger/internal/store"
+	"github.com/minhnghia2k3/exchanger/internal/webhook"
 	"log"
 	"log/slog"
 	"os"
+	"time"
 )
 
 const jsonData = `
@@ -727,6 +732,45 @@ func main() {
 			secret:        env.GetString("JWT_SECRET", ""),
 			expiry:        env.GetString("JWT_EXPIRY", "15"),
 			refreshExpiry: env.GetString("JWT_REFRESH_EXPIRY", "72h")},
+		providerConfig: providerConfig{
+			name:             env.GetString("RATE_PROVIDER", provider.ExchangeRateAPIName),
+			apiKey:           env.GetString("EXCHANGER_RATE_API", ""),
+			baseURL:          env.GetString("RATE_PROVIDER_URL", ""),
+			strategy:         env.GetString("RATE_PROVIDER_STRATEGY", provider.StrategyFailover),
+			tolerance:        env.GetFloat("RATE_CONSENSUS_TOLERANCE", 0.01),
+			breakerThreshold: env.GetInt("RATE_BREAKER_THRESHOLD", 3),
+			breakerCooldown:  env.GetString("RATE_BREAKER_COOLDOWN", "30s"),
+			cache:            env.GetBool("RATE_PROVIDER_CACHE", true),
+			budgets:          env.GetString("RATE_PROVIDER_BUDGETS", "exchangerate-api:0:1500"),
+		},
+		refresherConfig: refresherConfig{
+			interval:    env.GetString("REFRESH_INTERVAL", "1m"),
+			batchSize:   env.GetInt("REFRESH_BATCH_SIZE", 100),
+			concurrency: env.GetInt("REFRESH_CONCURRENCY", 4),
+		},
+		staleConfig: staleConfig{
+			policy:  env.GetString("RATE_STALE_POLICY", stalePolicyAllow),
+			refresh: env.GetBool("RATE_STALE_REFRESH", false),
+		},
+		alertConfig: alertConfig{
+			cooldown: env.GetString("ALERT_COOLDOWN", "1h"),
+		},
+		webhookConfig: webhookConfig{
+			allowHTTP:    env.GetBool("WEBHOOK_ALLOW_HTTP", false),
+			allowPrivate: env.GetBool("WEBHOOK_ALLOW_PRIVATE", false),
+			maxAttempts:  env.GetInt("WEBHOOK_MAX_ATTEMPTS", 5),
+			backoff:      env.GetString("WEBHOOK_BACKOFF", "1s"),
+			disableAfter: env.GetInt("WEBHOOK_DISABLE_AFTER", 5),
+			workers:      env.GetInt("WEBHOOK_WORKERS", 4),
+			queueSize:    env.GetInt("WEBHOOK_QUEUE_SIZE", 1000),
+		},
+		roundingConfig: roundingConfig{
+			mode: env.GetString("ROUNDING_MODE", string(decimal.HalfUp)),
+		},
+		taskConfig: taskConfig{
+			workers:   env.GetInt("BACKGROUND_WORKERS", 4),
+			queueSize: env.GetInt("BACKGROUND_QUEUE_SIZE", 1000),
+		},
 	}
 
 	// Logger
@@ -738,6 +782,27 @@ func main() {
 	handler := NewPrettyHandler(os.Stdout, opts)
 	logger := slog.New(handler)
 
+	if err := validStalePolicy(cfg.staleConfig.policy); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if _, err := decimal.ParseRoundingMode(cfg.roundingConfig.mode); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if _, err := time.ParseDuration(cfg.alertConfig.cooldown); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	webhookBackoff, err := time.ParseDuration(cfg.webhookConfig.backoff)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	// Database
 	db, err := database.ConnectDB(
 		cfg.dbConfig.dsn,
@@ -763,13 +828,64 @@ func main() {
 	// Storage (repository)
 	storage := store.NewStorage(db)
 
+	// Rate provider
+	budgets, err := provider.ParseBudgets(cfg.providerConfig.budgets)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+	meter := provider.NewMeter(storage.Usage, budgets)
+
+	rateProvider, err := provider.New(provider.Config{
+		Name:             cfg.providerConfig.name,
+		APIKey:           cfg.providerConfig.apiKey,
+		BaseURL:          cfg.providerConfig.baseURL,
+		Strategy:         cfg.providerConfig.strategy,
+		Tolerance:        cfg.providerConfig.tolerance,
+		BreakerThreshold: cfg.providerConfig.breakerThreshold,
+		BreakerCooldown:  cfg.providerConfig.breakerCooldown,
+		Cache:            cfg.providerConfig.cache,
+		Logger:           logger,
+		Meter:            meter,
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
 	app := application{
-		config: cfg,
-		store:  storage,
-		mailer: mailer,
-		logger: logger,
+		config:     cfg,
+		store:      storage,
+		mailer:     mailer,
+		provider:   rateProvider,
+		meter:      meter,
+		broker:     newRateBroker(),
+		graph:      &rateGraphCache{},
+		tasks:      newTaskQueue(cfg.taskConfig.workers, cfg.taskConfig.queueSize, logger),
+		deliveries: newTaskQueue(cfg.webhookConfig.workers, cfg.webhookConfig.queueSize, logger),
+		webhooks:   webhook.NewSender(cfg.webhookConfig.maxAttempts, webhookBackoff, cfg.webhookConfig.allowPrivate),
+		logger:     logger,
 	}
 
+	// Rate alerts are evaluated off the request path
+	storage.Rates.Subscribe(func(observation store.RateObservation) {
+		app.tasks.enqueue("rate alerts", func(ctx context.Context) {
+			app.evaluateAlerts(ctx, observation)
+		})
+	})
+	storage.Rates.Subscribe(app.broker.publish)
+	storage.Rates.Subscribe(func(store.RateObservation) {
+		app.graph.invalidate()
+	})
+	storage.Rates.Subscribe(func(observation store.RateObservation) {
+		app.tasks.enqueue("rate webhooks", func(ctx context.Context) {
+			app.dispatchWebhooks(ctx, observation)
+		})
+	})
+
 	// Serve application
-	log.Fatal(app.serve())
+	if err = app.serve(); err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"net/http"
+)
+
+type MarkupInput struct {
+	BidBps float64 `json:"bid_bps" validate:"gte=0,lt=10000"`
+	AskBps float64 `json:"ask_bps" validate:"gte=0,lt=10000"`
+}
+
+// List markups
+//
+//	@Summary		List markups
+//	@Description	get the default markup and every per-pair markup applied to the mid rate
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	[]store.Markup
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/markups [get]
+func (app *application) listMarkupsHandler(w http.ResponseWriter, r *http.Request) {
+	markups, err := app.store.Markups.List(r.Context())
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if markups == nil {
+		markups = []store.Markup{}
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, markups); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Set default markup
+//
+//	@Summary		Set default markup
+//	@Description	set the markup of pairs without their own and reprice them
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			input	body	MarkupInput	true	"Bid and ask markup in basis points"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Markup
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/markups/default [put]
+func (app *application) setDefaultMarkupHandler(w http.ResponseWriter, r *http.Request) {
+	app.setMarkup(w, r, &store.Markup{})
+}
+
+// Set pair markup
+//
+//	@Summary		Set pair markup
+//	@Description	set the markup of one pair, overriding the default, and reprice it
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path	string		true	"Base currency code"
+//	@Param			target	path	string		true	"Target currency code"
+//	@Param			input	body	MarkupInput	true	"Bid and ask markup in basis points"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Markup
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/markups/{base}/{target} [put]
+func (app *application) setPairMarkupHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	app.setMarkup(w, r, &store.Markup{BaseCode: base, TargetCode: target})
+}
+
+// Remove pair markup
+//
+//	@Summary		Remove pair markup
+//	@Description	remove the markup of one pair so it falls back to the default
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path	string	true	"Base currency code"
+//	@Param			target	path	string	true	"Target currency code"
+//	@Security		ApiKeyAuth
+//	@Success		204
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/admin/markups/{base}/{target} [delete]
+func (app *application) deletePairMarkupHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	if err := app.store.Markups.Delete(r.Context(), base, target); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	// Repriced bid and ask prices are not announced to rate listeners
+	app.graph.invalidate()
+
+	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// setMarkup reads the markup input into markup and stores it
+func (app *application) setMarkup(w http.ResponseWriter, r *http.Request, markup *store.Markup) {
+	var input MarkupInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	markup.BidBps = input.BidBps
+	markup.AskBps = input.AskBps
+
+	if err := app.store.Markups.Set(r.Context(), markup); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	// Repriced bid and ask prices are not announced to rate listeners
+	app.graph.invalidate()
+
+	if err := app.jsonResponse(w, http.StatusOK, markup); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
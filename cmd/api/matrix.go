@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/rategraph"
+)
+
+// maxMatrixCodes bounds the grid to maxMatrixCodes² cells
+const maxMatrixCodes = 50
+
+var (
+	errMissingCodes   = errors.New("codes is required")
+	errTooManyCodes   = fmt.Errorf("codes accepts at most %d currencies", maxMatrixCodes)
+	errDuplicatedCode = errors.New("codes must not repeat a currency")
+)
+
+type MatrixCell struct {
+	Rate       decimal.Decimal      `json:"rate"`
+	Provenance rategraph.Provenance `json:"provenance"`
+	Path       []string             `json:"path"`
+	LastUpdate time.Time            `json:"last_update"`
+}
+
+type RateMatrix struct {
+	Codes []string `json:"codes"`
+	// Rates[i][j] converts Codes[i] into Codes[j], null when no path exists
+	Rates [][]*MatrixCell `json:"rates"`
+}
+
+// Rate matrix
+//
+//	@Summary		Rate matrix
+//	@Description	convert between every pair of the given currencies, deriving pairs that are not stored
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			codes	query		string	true	"Comma separated currency codes"
+//	@Success		200		{object}	RateMatrix
+//	@Failure		400		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/rates/matrix [get]
+func (app *application) rateMatrixHandler(w http.ResponseWriter, r *http.Request) {
+	param := readString(r, "codes", "")
+	if param == "" {
+		app.badRequestResponse(w, r, errMissingCodes)
+		return
+	}
+
+	codes := strings.Split(param, ",")
+	if len(codes) > maxMatrixCodes {
+		app.badRequestResponse(w, r, errTooManyCodes)
+		return
+	}
+
+	seen := make(map[string]bool, len(codes))
+	for i, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if len(code) != 3 {
+			app.badRequestResponse(w, r, errInvalidCurrencyCode)
+			return
+		}
+		if seen[code] {
+			app.badRequestResponse(w, r, errDuplicatedCode)
+			return
+		}
+
+		seen[code] = true
+		codes[i] = code
+	}
+
+	graph, err := app.graph.get(r.Context(), app.store.Rates)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	matrix := RateMatrix{
+		Codes: codes,
+		Rates: make([][]*MatrixCell, len(codes)),
+	}
+
+	for i, base := range codes {
+		matrix.Rates[i] = make([]*MatrixCell, len(codes))
+
+		for j, target := range codes {
+			if i == j {
+				matrix.Rates[i][j] = &MatrixCell{Rate: decimal.One, Provenance: rategraph.Direct, Path: []string{base}}
+				continue
+			}
+
+			path, err := graph.Find(base, target)
+			if err != nil {
+				// Leave the cell empty, the rest of the grid is still useful
+				continue
+			}
+
+			matrix.Rates[i][j] = &MatrixCell{
+				Rate:       path.Rate.Round(decimal.Scale),
+				Provenance: path.Provenance(),
+				Path:       path.Codes(),
+				LastUpdate: path.LastUpdate,
+			}
+		}
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, matrix); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
@@ -16,6 +16,8 @@ const (
 	currencyCtx  = "currency"
 	userCtx      = "user"
 	foundUserCtx = "foundUser"
+	alertCtx     = "alert"
+	webhookCtx   = "webhook"
 )
 
 func (app *application) currencyContext(next http.Handler) http.Handler {
@@ -143,3 +145,83 @@ func (app *application) adminRequired(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+func (app *application) moderatorRequired(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		currentUser := r.Context().Value(userCtx).(*store.User)
+
+		if currentUser.Role.Level < 2 {
+			app.forbiddenResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ownerRequired lets users reach only their own resources, admins reach everyone's
+func (app *application) ownerRequired(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		currentUser := r.Context().Value(userCtx).(*store.User)
+		foundUser := r.Context().Value(foundUserCtx).(*store.User)
+
+		if currentUser.ID != foundUser.ID && currentUser.Role.Level < 3 {
+			app.forbiddenResponse(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (app *application) alertContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(foundUserCtx).(*store.User)
+
+		alertID, err := strconv.ParseInt(chi.URLParam(r, "alertID"), 10, 64)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		alert, err := app.store.Alerts.Get(r.Context(), user.ID, alertID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), alertCtx, alert)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (app *application) webhookContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := r.Context().Value(foundUserCtx).(*store.User)
+
+		webhookID, err := strconv.ParseInt(chi.URLParam(r, "webhookID"), 10, 64)
+		if err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+
+		hook, err := app.store.Webhooks.Get(r.Context(), user.ID, webhookID)
+		if err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, err)
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), webhookCtx, hook)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"net/http"
+	"time"
+)
+
+var (
+	errOverrideExpired = errors.New("expires_at must be in the future")
+	errNoOverride      = errors.New("the pair has no active manual rate")
+)
+
+type OverrideRateInput struct {
+	Rate      decimal.Decimal `json:"rate" validate:"required,gt=0"`
+	ExpiresAt *time.Time      `json:"expires_at"`
+}
+
+// Override exchange rate
+//
+//	@Summary		Override exchange rate
+//	@Description	set a manual rate that automatic refreshes will not overwrite until it expires
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path	string				true	"Base currency code"
+//	@Param			target	path	string				true	"Target currency code"
+//	@Param			input	body	OverrideRateInput	true	"Manual rate"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.ExchangeRate
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/{base}/{target}/override [put]
+func (app *application) overrideExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+	currentUser := r.Context().Value(userCtx).(*store.User)
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	var input OverrideRateInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	now := time.Now().UTC()
+	if input.ExpiresAt != nil {
+		expiresAt := input.ExpiresAt.UTC()
+		input.ExpiresAt = &expiresAt
+	}
+
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		app.badRequestResponse(w, r, errOverrideExpired)
+		return
+	}
+
+	for _, code := range []string{base, target} {
+		if _, err := isSupportedCode(r.Context(), app.store.Currencies, code); err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, fmt.Errorf(errUnsupportedCurrencyFmt, code))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	// The pair becomes due again once the override expires
+	nextUpdate := store.NeverUpdate
+	if input.ExpiresAt != nil {
+		nextUpdate = *input.ExpiresAt
+	}
+
+	rate := &store.ExchangeRate{
+		BaseCode:          base,
+		TargetCode:        target,
+		Rate:              input.Rate,
+		LastUpdate:        now,
+		NextUpdate:        nextUpdate,
+		SetBy:             &currentUser.ID,
+		OverrideExpiresAt: input.ExpiresAt,
+	}
+
+	if err := app.store.Rates.Override(r.Context(), rate); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, rate); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Remove exchange rate override
+//
+//	@Summary		Remove exchange rate override
+//	@Description	expire the manual rate so the next refresh fetches the pair from the provider
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path	string	true	"Base currency code"
+//	@Param			target	path	string	true	"Target currency code"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.ExchangeRate
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/{base}/{target}/override [delete]
+func (app *application) removeOverrideHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	rate, err := app.store.Rates.GetByPair(r.Context(), base, target)
+	if err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	now := time.Now().UTC()
+	if !rate.OverrideActive(now) {
+		app.notFoundResponse(w, r, errNoOverride)
+		return
+	}
+
+	rate.OverrideExpiresAt = &now
+	rate.NextUpdate = now
+
+	if err = app.store.Rates.Update(r.Context(), rate); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, rate); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
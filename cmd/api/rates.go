@@ -5,19 +5,86 @@ import (
 	"errors"
 	"fmt"
 	"github.com/go-chi/chi/v5"
-	"github.com/minhnghia2k3/exchanger/internal/env"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
 	"github.com/minhnghia2k3/exchanger/internal/store"
 	"net/http"
+	"strconv"
 	"time"
 )
 
 var (
-	errInvalidCurrencyCode     = errors.New("invalid currency code")
-	errPairAlreadyExists       = errors.New("the pair of exchange rate is already exists")
-	errUnsupportedCurrencyCode = errors.New("unsupported currency code")
-	errUnsupportedCurrencyFmt  = "%s code not supported"
+	errInvalidCurrencyCode    = errors.New("invalid currency code")
+	errPairAlreadyExists      = errors.New("the pair of exchange rate is already exists")
+	errOverrideActive         = store.ErrOverrideActive
+	errInvalidStale           = errors.New("stale must be true or false")
+	errUnsupportedCurrencyFmt = "%s code not supported"
 )
 
+// List exchange rates
+//
+//	@Summary		List exchange rates
+//	@Description	list the stored pairs with optional base, target and staleness filters
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base		query	string	false	"Base currency code"
+//	@Param			target		query	string	false	"Target currency code"
+//	@Param			stale		query	bool	false	"Only stale (true) or fresh (false) pairs"
+//	@Param			page		query	int		false	"Current page"
+//	@Param			page_size	query	int		false	"Page size"
+//	@Param			sort		query	string	false	"Sort"	Enums(last_update, -last_update, rate, -rate)
+//	@Success		200
+//	@Failure		400	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates [get]
+func (app *application) listExchangeRatesHandler(w http.ResponseWriter, r *http.Request) {
+	base := readString(r, "base", "")
+	target := readString(r, "target", "")
+
+	if (base != "" && len(base) != 3) || (target != "" && len(target) != 3) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	var stale *bool
+	if val := r.URL.Query().Get("stale"); val != "" {
+		parsed, err := strconv.ParseBool(val)
+		if err != nil {
+			app.badRequestResponse(w, r, errInvalidStale)
+			return
+		}
+		stale = &parsed
+	}
+
+	input := store.Filter{
+		Page:         readInt(r, "page", 1),
+		PageSize:     readInt(r, "page_size", 10),
+		Sort:         readString(r, "sort", "id"),
+		SortSafeList: []string{"id", "last_update", "rate", "base_code", "target_code"},
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	list, metadata, err := app.store.Rates.List(r.Context(), base, target, stale, input)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	data := make([]RateResponse, len(list))
+	for i := range list {
+		data[i] = RateResponse{&list[i], staleness(list[i].LastUpdate, list[i].NextUpdate, now)}
+	}
+
+	if err = writeJSON(w, http.StatusOK, envelop{"metadata": metadata, "data": data}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
 // Get exchange rate by code
 //
 //	@Summary		Get exchange rate
@@ -28,7 +95,7 @@ var (
 //	@Param			base	path	string	true	"Base currency code"
 //	@Param			target	path	string	true	"Target currency code"
 //	@Security		ApiKeyAuth
-//	@Success		200	{object}	store.ExchangeRate
+//	@Success		200	{object}	RateResponse
 //	@Failure		400	{object}	error
 //	@Failure		401	{object}	error
 //	@Failure		404	{object}	error
@@ -54,22 +121,13 @@ func (app *application) getExchangeRatesHandler(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, rate); err != nil {
+	response := RateResponse{rate, staleness(rate.LastUpdate, rate.NextUpdate, time.Now())}
+
+	if err = app.jsonResponse(w, http.StatusOK, response); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
 
-type ExchangeRateData struct {
-	Result             string  `json:"result"`
-	TimeLastUpdateUnix int     `json:"time_last_update_unix"`
-	TimeLastUpdateUtc  string  `json:"time_last_update_utc"`
-	TimeNextUpdateUnix int     `json:"time_next_update_unix"`
-	TimeNextUpdateUtc  string  `json:"time_next_update_utc"`
-	BaseCode           string  `json:"base_code"`
-	TargetCode         string  `json:"target_code"`
-	ConversionRate     float64 `json:"conversion_rate"`
-}
-
 // Add exchange rate of pair
 //
 //	@Summary		Add exchange rate
@@ -121,26 +179,15 @@ func (app *application) addExchangeRateHandler(w http.ResponseWriter, r *http.Re
 		}
 	}
 
-	// 3. Get exchange rate from api
-	url := fmt.Sprintf("https://v6.exchangerate-api.com/v6/%s/pair/%s/%s",
-		env.GetString("EXCHANGER_RATE_API", ""),
-		base,
-		target,
-	)
-
-	var data ExchangeRateData
-	err = GetAPI(url, &data)
+	// 3. Get exchange rate from provider
+	data, err := app.provider.GetPair(r.Context(), base, target)
 	if err != nil {
-		app.internalServerError(w, r, err)
+		app.providerErrorResponse(w, r, err)
 		return
 	}
 
-	// 3. Parse date and store exchange rate to db
-	exchangeRate, err := buildExchangeRate(data, base, target)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
+	// 4. Store exchange rate to db
+	exchangeRate := data.ExchangeRate()
 
 	err = app.store.Rates.Save(r.Context(), exchangeRate)
 	if err != nil {
@@ -153,24 +200,16 @@ func (app *application) addExchangeRateHandler(w http.ResponseWriter, r *http.Re
 	}
 }
 
-func buildExchangeRate(data ExchangeRateData, base, target string) (*store.ExchangeRate, error) {
-	lastUpdate, err := time.Parse(time.RFC1123, data.TimeLastUpdateUtc)
-	if err != nil {
-		return nil, err
-	}
-
-	nextUpdate, err := time.Parse(time.RFC1123, data.TimeNextUpdateUtc)
-	if err != nil {
-		return nil, err
+func (app *application) providerErrorResponse(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, provider.ErrUnsupportedCode):
+		app.badRequestResponse(w, r, err)
+	case errors.Is(err, provider.ErrCircuitOpen), errors.Is(err, provider.ErrRateLimited),
+		errors.Is(err, provider.ErrBudgetExhausted):
+		app.serviceUnavailableResponse(w, r, err)
+	default:
+		app.internalServerError(w, r, err)
 	}
-
-	return &store.ExchangeRate{
-		NextUpdate: nextUpdate,
-		BaseCode:   base,
-		TargetCode: target,
-		LastUpdate: lastUpdate,
-		Rate:       data.ConversionRate,
-	}, nil
 }
 
 func validCurrencyCode(base, target string) bool {
@@ -209,11 +248,12 @@ func isSupportedCode(ctx context.Context, currencies store.ICurrencies, code str
 //	@Param			base	path	string	true	"Base currency code"
 //	@Param			target	path	string	true	"Target currency code"
 //	@Security		ApiKeyAuth
-//	@Success		200	{object}	store.ExchangeRate
+//	@Success		200	{object}	RateResponse
 //	@Failure		400	{object}	error
 //	@Failure		401	{object}	error
 //	@Failure		403	{object}	error
 //	@Failure		404	{object}	error
+//	@Failure		409	{object}	error
 //	@Failure		500	{object}	error
 //	@Router			/rates/{base}/{target} [patch]
 func (app *application) updateExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
@@ -236,52 +276,22 @@ func (app *application) updateExchangeRateHandler(w http.ResponseWriter, r *http
 		return
 	}
 
-	url := fmt.Sprintf("https://v6.exchangerate-api.com/v6/%s/pair/%s/%s",
-		env.GetString("EXCHANGER_RATE_API", ""),
-		base,
-		target,
-	)
-
-	var data ExchangeRateData
-	err = GetAPI(url, &data)
-	if err != nil {
-		switch {
-		case errors.Is(err, errUnsupportedCurrencyCode):
-			app.badRequestResponse(w, r, err)
-		default:
-			app.internalServerError(w, r, err)
-		}
-		return
-	}
-
-	lastUpdate, err := time.Parse(time.RFC1123, data.TimeLastUpdateUtc)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
-	nextUpdate, err := time.Parse(time.RFC1123, data.TimeNextUpdateUtc)
-	if err != nil {
-		app.internalServerError(w, r, err)
-		return
-	}
-
-	rate.LastUpdate = lastUpdate
-	rate.NextUpdate = nextUpdate
-	rate.Rate = data.ConversionRate
-
-	err = app.store.Rates.Update(r.Context(), rate)
+	err = app.refreshRate(r.Context(), rate)
 	if err != nil {
 		switch {
 		case errors.Is(err, store.ErrNotFound):
 			app.notFoundResponse(w, r, err)
+		case errors.Is(err, errOverrideActive):
+			app.conflictErrorResponse(w, r, err)
 		default:
-			app.internalServerError(w, r, err)
+			app.providerErrorResponse(w, r, err)
 		}
 		return
 	}
 
-	if err = app.jsonResponse(w, http.StatusOK, rate); err != nil {
+	response := RateResponse{rate, staleness(rate.LastUpdate, rate.NextUpdate, time.Now())}
+
+	if err = app.jsonResponse(w, http.StatusOK, response); err != nil {
 		app.internalServerError(w, r, err)
 	}
 }
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// runRefresher refreshes pairs whose next update has passed until ctx is done
+func (app *application) runRefresher(ctx context.Context) {
+	interval, err := time.ParseDuration(app.config.refresherConfig.interval)
+	if err != nil || interval <= 0 {
+		app.logger.LogAttrs(ctx,
+			slog.LevelWarn,
+			"Rate refresher disabled",
+			slog.String("interval", app.config.refresherConfig.interval),
+		)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			app.logger.LogAttrs(context.Background(), slog.LevelInfo, "Rate refresher stopped")
+			return
+		case <-ticker.C:
+			app.refreshDueRates(ctx)
+		}
+	}
+}
+
+// refreshDueRates refreshes one batch of due pairs with bounded concurrency
+func (app *application) refreshDueRates(ctx context.Context) {
+	start := time.Now()
+
+	rates, err := app.store.Rates.ListDue(ctx, start.UTC(), app.config.refresherConfig.batchSize)
+	if err != nil {
+		app.logger.LogAttrs(ctx,
+			slog.LevelError,
+			"Rate refresh cycle failed",
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+
+	if len(rates) == 0 {
+		return
+	}
+
+	// Cancelled when the provider budget runs out, the rest waits for the next cycle
+	cycleCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var refreshed, failed, deferred atomic.Int64
+	sem := make(chan struct{}, max(app.config.refresherConfig.concurrency, 1))
+
+	for i := range rates {
+		sem <- struct{}{}
+		if cycleCtx.Err() != nil {
+			<-sem
+			deferred.Add(int64(len(rates) - i))
+			break
+		}
+
+		wg.Add(1)
+
+		go func(rate *store.ExchangeRate) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := app.refreshRate(cycleCtx, rate); err != nil {
+				if errors.Is(err, errOverrideActive) {
+					return
+				}
+				if errors.Is(err, provider.ErrBudgetExhausted) || errors.Is(err, context.Canceled) {
+					deferred.Add(1)
+					cancel()
+					return
+				}
+
+				failed.Add(1)
+				app.logger.LogAttrs(ctx,
+					slog.LevelWarn,
+					"Rate refresh failed",
+					slog.String("pair", rate.BaseCode+"/"+rate.TargetCode),
+					slog.String("error", err.Error()),
+				)
+				return
+			}
+			refreshed.Add(1)
+		}(&rates[i])
+	}
+
+	wg.Wait()
+
+	app.logger.LogAttrs(ctx,
+		slog.LevelInfo,
+		"Rate refresh cycle",
+		slog.Int("due", len(rates)),
+		slog.Int64("refreshed", refreshed.Load()),
+		slog.Int64("failed", failed.Load()),
+		slog.Int64("deferred", deferred.Load()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// refreshRate fetches the pair from the provider and stores the new rate.
+// Pairs under an active manual override are left untouched.
+func (app *application) refreshRate(ctx context.Context, rate *store.ExchangeRate) error {
+	if rate.OverrideActive(time.Now()) {
+		return errOverrideActive
+	}
+
+	data, err := app.provider.GetPair(ctx, rate.BaseCode, rate.TargetCode)
+	if err != nil {
+		return err
+	}
+
+	rate.LastUpdate = data.LastUpdate
+	rate.NextUpdate = data.NextUpdate
+	rate.Rate = data.Rate
+	rate.Sources = data.Sources
+	rate.Spread = data.Spread
+	rate.Source = data.Source()
+	rate.SetBy = nil
+	rate.OverrideExpiresAt = nil
+
+	// Refresh refuses to replace an override set during the provider call
+	return app.store.Rates.Refresh(ctx, rate)
+}
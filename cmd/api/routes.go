@@ -43,6 +43,36 @@ func (app *application) routes() http.Handler {
 
 				r.Get("/", app.getUserHandler)
 				r.Delete("/", app.deleteUserHandler)
+
+				r.Route("/alerts", func(r chi.Router) {
+					r.Use(app.ownerRequired)
+
+					r.Get("/", app.listAlertsHandler)
+					r.Post("/", app.createAlertHandler)
+					r.Route("/{alertID}", func(r chi.Router) {
+						r.Use(app.alertContext)
+
+						r.Get("/", app.getAlertHandler)
+						r.Patch("/", app.updateAlertHandler)
+						r.Delete("/", app.deleteAlertHandler)
+					})
+				})
+
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Use(app.ownerRequired)
+
+					r.Get("/", app.listWebhooksHandler)
+					r.Post("/", app.createWebhookHandler)
+					r.Route("/{webhookID}", func(r chi.Router) {
+						r.Use(app.webhookContext)
+
+						r.Get("/", app.getWebhookHandler)
+						r.Patch("/", app.updateWebhookHandler)
+						r.Delete("/", app.deleteWebhookHandler)
+						r.Get("/deliveries", app.webhookDeliveriesHandler)
+						r.Post("/test", app.testWebhookHandler)
+					})
+				})
 			})
 		})
 
@@ -58,16 +88,49 @@ func (app *application) routes() http.Handler {
 			})
 		})
 		r.Route("/rates", func(r chi.Router) {
+			r.Get("/", app.listExchangeRatesHandler)
+			r.Get("/matrix", app.rateMatrixHandler)
+			r.Get("/stream", app.rateStreamHandler)
+			r.Group(func(r chi.Router) {
+				r.Use(app.validateAccessToken, app.adminRequired)
+
+				r.Post("/import", app.importRatesHandler)
+				r.Get("/export", app.exportRatesHandler)
+			})
 			r.Route("/{base}/{target}", func(r chi.Router) {
 				r.Get("/", app.getExchangeRatesHandler)
 				r.Post("/", app.addExchangeRateHandler)
 				r.Patch("/", app.updateExchangeRateHandler)
 				//r.Delete("/", app.deleteExchangeHandler)
+				r.Get("/history", app.rateHistoryHandler)
+				r.Get("/candles", app.rateCandlesHandler)
+				r.Get("/stats", app.rateStatsHandler)
+
+				r.Route("/override", func(r chi.Router) {
+					r.Use(app.validateAccessToken, app.moderatorRequired)
+
+					r.Put("/", app.overrideExchangeRateHandler)
+					r.Delete("/", app.removeOverrideHandler)
+				})
 			})
 		})
 		r.Route("/exchanges/pair", func(r chi.Router) {
 			r.Get("/{base}/{target}/{amount}", app.exchangePairHandler)
 		})
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(app.validateAccessToken, app.adminRequired)
+
+			r.Get("/providers", app.providerStatusHandler)
+			r.Get("/providers/usage", app.providerUsageHandler)
+			r.Post("/rates/{base}/latest", app.ingestLatestRatesHandler)
+
+			r.Route("/markups", func(r chi.Router) {
+				r.Get("/", app.listMarkupsHandler)
+				r.Put("/default", app.setDefaultMarkupHandler)
+				r.Put("/{base}/{target}", app.setPairMarkupHandler)
+				r.Delete("/{base}/{target}", app.deletePairMarkupHandler)
+			})
+		})
 	})
 
 	return r
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/rategraph"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+)
+
+// Staleness policies, chosen per deployment with RATE_STALE_POLICY
+const (
+	stalePolicyAllow  = "allow"
+	stalePolicyWarn   = "warn"
+	stalePolicyReject = "reject"
+)
+
+var errStaleRate = errors.New("the rate is past its scheduled update")
+
+type Staleness struct {
+	// AgeSeconds is the time since the rate was observed
+	AgeSeconds int64 `json:"age_seconds"`
+	// IsStale is set once the rate is past its next scheduled update
+	IsStale bool `json:"is_stale"`
+}
+
+// RateResponse is a stored rate with its staleness at the time of the request
+type RateResponse struct {
+	*store.ExchangeRate
+	Staleness
+}
+
+func staleness(lastUpdate, nextUpdate, now time.Time) Staleness {
+	return Staleness{
+		AgeSeconds: int64(now.Sub(lastUpdate).Seconds()),
+		IsStale:    nextUpdate.Before(now),
+	}
+}
+
+func validStalePolicy(policy string) error {
+	switch policy {
+	case stalePolicyAllow, stalePolicyWarn, stalePolicyReject:
+		return nil
+	default:
+		return fmt.Errorf("unknown stale policy %q", policy)
+	}
+}
+
+// applyStalePolicy adds the warning headers or rejects the conversion, depending on the configured policy
+func (app *application) applyStalePolicy(w http.ResponseWriter, s Staleness) error {
+	if !s.IsStale {
+		return nil
+	}
+
+	switch app.config.staleConfig.policy {
+	case stalePolicyWarn:
+		w.Header().Set("Warning", `110 - "Response is Stale"`)
+		w.Header().Set("X-Rate-Age", strconv.FormatInt(s.AgeSeconds, 10))
+	case stalePolicyReject:
+		return errStaleRate
+	}
+
+	return nil
+}
+
+// refreshPath refreshes, through the provider, every stored pair of the path past its next update
+func (app *application) refreshPath(ctx context.Context, path *rategraph.Path) error {
+	now := time.Now()
+
+	for _, hop := range path.Hops {
+		if !hop.NextUpdate.Before(now) {
+			continue
+		}
+
+		base, target := hop.From, hop.To
+		if hop.Inverse {
+			base, target = target, base
+		}
+
+		rate, err := app.store.Rates.GetByPair(ctx, base, target)
+		if err != nil {
+			return err
+		}
+
+		err = app.refreshRate(ctx, rate)
+		if err != nil && !errors.Is(err, errOverrideActive) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/minhnghia2k3/exchanger/internal/analytics"
+)
+
+const (
+	defaultStatsWindow = 30 * 24 * time.Hour
+	// maxStatsObservations bounds the series loaded for one request, the latest are kept
+	maxStatsObservations = 10000
+	maxStatsPeriod       = 500
+)
+
+var errInvalidPeriod = fmt.Errorf("period must be between 1 and %d", maxStatsPeriod)
+
+// Exchange rate statistics
+//
+//	@Summary		Exchange rate statistics
+//	@Description	change, range, volatility and moving averages of a pair over a window of its rate history.
+//	@Description	The window defaults to the 30 days before to, which defaults to now.
+//	@Tags			Exchange Rates
+//	@Accept			json
+//	@Produce		json
+//	@Param			base	path		string	true	"Base currency code"
+//	@Param			target	path		string	true	"Target currency code"
+//	@Param			from	query		string	false	"Start time (RFC 3339)"
+//	@Param			to		query		string	false	"End time (RFC 3339)"
+//	@Param			period	query		int		false	"Observations spanned by the moving averages"
+//	@Success		200		{object}	analytics.Stats
+//	@Failure		400		{object}	error
+//	@Failure		404		{object}	error
+//	@Failure		500		{object}	error
+//	@Router			/rates/{base}/{target}/stats [get]
+func (app *application) rateStatsHandler(w http.ResponseWriter, r *http.Request) {
+	base := chi.URLParam(r, "base")
+	target := chi.URLParam(r, "target")
+
+	if !validCurrencyCode(base, target) {
+		app.badRequestResponse(w, r, errInvalidCurrencyCode)
+		return
+	}
+
+	from, err := readTime(r, "from")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	to, err := readTime(r, "to")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultStatsWindow)
+	}
+
+	if from.After(to) {
+		app.badRequestResponse(w, r, errInvalidTimeRange)
+		return
+	}
+
+	period := readInt(r, "period", 20)
+	if period < 1 || period > maxStatsPeriod {
+		app.badRequestResponse(w, r, errInvalidPeriod)
+		return
+	}
+
+	observations, err := app.store.Rates.Series(r.Context(), base, target, from, to, maxStatsObservations)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	points := make([]analytics.Point, len(observations))
+	for i, o := range observations {
+		points[i] = analytics.Point{Time: o.ObservedAt, Rate: o.Rate.Float64()}
+	}
+
+	stats, err := analytics.Summarize(points, period)
+	if err != nil {
+		switch {
+		case errors.Is(err, analytics.ErrNoData):
+			app.notFoundResponse(w, r, fmt.Errorf("%w for %s/%s", err, base, target))
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, stats); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
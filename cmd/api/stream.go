@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/store"
+)
+
+const (
+	streamHeartbeat = 15 * time.Second
+	// streamBuffer is how far a client may lag before it is dropped, it then
+	// reconnects and catches up through Last-Event-ID
+	streamBuffer = 64
+	// streamReplayLimit bounds the events replayed on resume
+	streamReplayLimit = 1000
+	// streamReplayLookback covers the longest rate write transaction, a bulk
+	// import, whose lower ids may commit after a later event was sent
+	streamReplayLookback = time.Minute
+	maxStreamPairs       = 100
+)
+
+var (
+	errMissingPairs    = errors.New("pairs is required")
+	errTooManyPairs    = fmt.Errorf("pairs accepts at most %d pairs", maxStreamPairs)
+	errInvalidPair     = errors.New("pairs must look like USD-EUR,USD-JPY")
+	errInvalidEventID  = errors.New("Last-Event-ID must be an event id")
+	errStreamingDenied = errors.New("streaming is not supported")
+)
+
+// rateBroker fans recorded rate observations out to the open streams. It is
+// fed by the rate store of this process only: rates written by cmd/ingest or
+// another API instance are not pushed live, clients receive them on the
+// replay of their next reconnect.
+type rateBroker struct {
+	mu          sync.Mutex
+	subscribers map[*rateSubscriber]struct{}
+	closed      bool
+}
+
+type rateSubscriber struct {
+	pairs  map[store.Pair]bool
+	events chan store.RateObservation
+}
+
+func newRateBroker() *rateBroker {
+	return &rateBroker{subscribers: make(map[*rateSubscriber]struct{})}
+}
+
+func (b *rateBroker) subscribe(pairs []store.Pair) *rateSubscriber {
+	sub := &rateSubscriber{
+		pairs:  make(map[store.Pair]bool, len(pairs)),
+		events: make(chan store.RateObservation, streamBuffer),
+	}
+	for _, pair := range pairs {
+		sub.pairs[pair] = true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(sub.events)
+		return sub
+	}
+
+	b.subscribers[sub] = struct{}{}
+
+	return sub
+}
+
+func (b *rateBroker) unsubscribe(sub *rateSubscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subscribers[sub]; ok {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// publish never blocks, a subscriber whose buffer is full is disconnected
+func (b *rateBroker) publish(observation store.RateObservation) {
+	pair := store.Pair{BaseCode: observation.BaseCode, TargetCode: observation.TargetCode}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subscribers {
+		if !sub.pairs[pair] {
+			continue
+		}
+
+		select {
+		case sub.events <- observation:
+		default:
+			delete(b.subscribers, sub)
+			close(sub.events)
+		}
+	}
+}
+
+// close ends every stream, so that they do not hold up a graceful shutdown
+func (b *rateBroker) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for sub := range b.subscribers {
+		delete(b.subscribers, sub)
+		close(sub.events)
+	}
+}
+
+// Stream rate updates
+//
+//	@Summary		Stream rate updates
+//	@Description	push a server-sent event whenever a subscribed pair is written. Comment lines are sent
+//	@Description	as heartbeats. Reconnecting with Last-Event-ID replays the events missed meanwhile, events
+//	@Description	recorded shortly before it may be sent again. Only rates written by this instance are pushed
+//	@Description	live, rates written by the ingest job or other instances are delivered on reconnect.
+//	@Tags			Exchange Rates
+//	@Produce		text/event-stream
+//	@Param			pairs			query	string	true	"Comma separated pairs, e.g. USD-EUR,USD-JPY"
+//	@Param			Last-Event-ID	header	int		false	"Resume after this event"
+//	@Success		200
+//	@Failure		400	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/rates/stream [get]
+func (app *application) rateStreamHandler(w http.ResponseWriter, r *http.Request) {
+	pairs, err := readPairs(r, "pairs")
+	if err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	var lastID int64
+	if val := r.Header.Get("Last-Event-ID"); val != "" {
+		lastID, err = strconv.ParseInt(val, 10, 64)
+		if err != nil || lastID < 0 {
+			app.badRequestResponse(w, r, errInvalidEventID)
+			return
+		}
+	}
+
+	rc := http.NewResponseController(w)
+	// Streams outlive the server write timeout
+	if err = rc.SetWriteDeadline(time.Time{}); err != nil {
+		app.internalServerError(w, r, errStreamingDenied)
+		return
+	}
+
+	// Subscribe before replaying so nothing recorded in between is lost
+	sub := app.broker.subscribe(pairs)
+	defer app.broker.unsubscribe(sub)
+
+	var missed []store.RateObservation
+	if lastID > 0 {
+		missed, err = app.store.Rates.Since(r.Context(), lastID, streamReplayLookback, pairs,
+			streamReplayLimit)
+		if err != nil {
+			app.internalServerError(w, r, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Ids are not published in order, so live events are only checked
+	// against the ones the replay already sent
+	replayed := make(map[int64]bool, len(missed))
+	for _, observation := range missed {
+		if err = writeRateEvent(w, observation); err != nil {
+			return
+		}
+		replayed[observation.ID] = true
+	}
+
+	if err = rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case observation, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			// Already sent by the replay
+			if replayed[observation.ID] {
+				delete(replayed, observation.ID)
+				continue
+			}
+
+			if err = writeRateEvent(w, observation); err != nil {
+				return
+			}
+		}
+
+		if err = rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeRateEvent(w http.ResponseWriter, observation store.RateObservation) error {
+	data, err := json.Marshal(observation)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: rate\ndata: %s\n\n", observation.ID, data)
+
+	return err
+}
+
+// readPairs parses a comma separated list of BASE-TARGET pairs
+func readPairs(r *http.Request, key string) ([]store.Pair, error) {
+	param := readString(r, key, "")
+	if param == "" {
+		return nil, errMissingPairs
+	}
+
+	values := strings.Split(param, ",")
+	if len(values) > maxStreamPairs {
+		return nil, errTooManyPairs
+	}
+
+	pairs := make([]store.Pair, len(values))
+	for i, value := range values {
+		base, target, ok := strings.Cut(strings.ToUpper(strings.TrimSpace(value)), "-")
+		if !ok || !validCurrencyCode(base, target) {
+			return nil, errInvalidPair
+		}
+
+		pairs[i] = store.Pair{BaseCode: base, TargetCode: target}
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// task is a unit of background work, run with a context that is cancelled
+// when the queue gives up draining on shutdown
+type task struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+// taskQueue runs work triggered by rate changes, such as alert evaluation or
+// webhook deliveries, on a fixed number of workers. Tasks that do not fit in
+// the queue are dropped instead of blocking the writer of the rate.
+type taskQueue struct {
+	tasks  chan task
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func newTaskQueue(workers, size int, logger *slog.Logger) *taskQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &taskQueue{
+		tasks:  make(chan task, max(size, 0)),
+		ctx:    ctx,
+		cancel: cancel,
+		logger: logger,
+	}
+
+	for range max(workers, 1) {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *taskQueue) work() {
+	defer q.wg.Done()
+
+	for t := range q.tasks {
+		t.run(q.ctx)
+	}
+}
+
+// enqueue schedules run, it is dropped with a warning when the queue is full or shut down
+func (q *taskQueue) enqueue(name string, run func(ctx context.Context)) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if !q.closed {
+		select {
+		case q.tasks <- task{name: name, run: run}:
+			return
+		default:
+		}
+	}
+
+	q.logger.LogAttrs(context.Background(),
+		slog.LevelWarn,
+		"Background task dropped",
+		slog.String("task", name),
+		slog.Bool("shutting_down", q.closed),
+	)
+}
+
+// shutdown stops accepting tasks and waits for the queued ones to finish.
+// Once ctx is done the running tasks are cancelled.
+func (q *taskQueue) shutdown(ctx context.Context) {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.tasks)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+	}
+
+	q.cancel()
+}
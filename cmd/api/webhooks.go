@@ -0,0 +1,495 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"github.com/minhnghia2k3/exchanger/internal/webhook"
+)
+
+var errInsecureWebhookURL = errors.New("url must use https")
+
+type CreateWebhookInput struct {
+	URL        string `json:"url" validate:"required,url"`
+	BaseCode   string `json:"base_code" validate:"required,len=3"`
+	TargetCode string `json:"target_code" validate:"required,len=3"`
+	// Threshold is the move in percent since the last notification that triggers the next one
+	Threshold float64 `json:"threshold" validate:"gte=0"`
+}
+
+type UpdateWebhookInput struct {
+	URL       *string  `json:"url" validate:"omitempty,url"`
+	Threshold *float64 `json:"threshold" validate:"omitempty,gte=0"`
+	// Active set to true re-enables a webhook disabled after repeated failures
+	Active *bool `json:"active"`
+}
+
+// WebhookWithSecret is returned once, on creation, so that the receiver can verify signatures
+type WebhookWithSecret struct {
+	store.Webhook
+	Secret string `json:"secret"`
+}
+
+// RateChangedData is the payload of rate.changed events
+type RateChangedData struct {
+	store.RateObservation
+	PreviousRate  *decimal.Decimal `json:"previous_rate"`
+	ChangePercent float64          `json:"change_percent"`
+}
+
+// List webhooks
+//
+//	@Summary		List webhooks
+//	@Description	list the webhooks of a user
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int	true	"user ID"
+//	@Security		ApiKeyAuth
+//	@Success		200	{array}		store.Webhook
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks [get]
+func (app *application) listWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(foundUserCtx).(*store.User)
+
+	webhooks, err := app.store.Webhooks.List(r.Context(), user.ID)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, webhooks); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Create webhook
+//
+//	@Summary		Create webhook
+//	@Description	post a signed rate.changed event to url whenever the pair moves by more than threshold percent
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID	path	int					true	"user ID"
+//	@Param			input	body	CreateWebhookInput	true	"Webhook"
+//	@Security		ApiKeyAuth
+//	@Success		201	{object}	WebhookWithSecret
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks [post]
+func (app *application) createWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	user := r.Context().Value(foundUserCtx).(*store.User)
+
+	var input CreateWebhookInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := app.validWebhookURL(r.Context(), input.URL); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	for _, code := range []string{input.BaseCode, input.TargetCode} {
+		if _, err := isSupportedCode(r.Context(), app.store.Currencies, code); err != nil {
+			switch {
+			case errors.Is(err, store.ErrNotFound):
+				app.notFoundResponse(w, r, fmt.Errorf(errUnsupportedCurrencyFmt, code))
+			default:
+				app.internalServerError(w, r, err)
+			}
+			return
+		}
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	hook := store.Webhook{
+		UserID:     user.ID,
+		URL:        input.URL,
+		Secret:     secret,
+		BaseCode:   input.BaseCode,
+		TargetCode: input.TargetCode,
+		Threshold:  input.Threshold,
+		Active:     true,
+	}
+
+	// Changes are measured from the rate current when the webhook is created
+	rate, err := app.store.Rates.GetByPair(r.Context(), input.BaseCode, input.TargetCode)
+	switch {
+	case err == nil:
+		hook.LastRate = &rate.Rate
+	case !errors.Is(err, store.ErrNotFound):
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.store.Webhooks.Create(r.Context(), &hook); err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusCreated, WebhookWithSecret{hook, secret}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Get webhook
+//
+//	@Summary		Get webhook
+//	@Description	get a webhook of a user
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID		path	int	true	"user ID"
+//	@Param			webhookID	path	int	true	"webhook ID"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Webhook
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks/{webhookID} [get]
+func (app *application) getWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := r.Context().Value(webhookCtx).(*store.Webhook)
+
+	if err := app.jsonResponse(w, http.StatusOK, hook); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Update webhook
+//
+//	@Summary		Update webhook
+//	@Description	change the url or threshold of a webhook, or disable and re-enable it
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID		path	int					true	"user ID"
+//	@Param			webhookID	path	int					true	"webhook ID"
+//	@Param			input		body	UpdateWebhookInput	true	"Changed fields"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.Webhook
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks/{webhookID} [patch]
+func (app *application) updateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := r.Context().Value(webhookCtx).(*store.Webhook)
+
+	var input UpdateWebhookInput
+
+	if err := app.readJSON(w, r, &input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	if input.URL != nil {
+		if err := app.validWebhookURL(r.Context(), *input.URL); err != nil {
+			app.badRequestResponse(w, r, err)
+			return
+		}
+		hook.URL = *input.URL
+	}
+	if input.Threshold != nil {
+		hook.Threshold = *input.Threshold
+	}
+	if input.Active != nil {
+		// Re-enabling starts counting failures afresh
+		if *input.Active && !hook.Active {
+			hook.FailureCount = 0
+			hook.DisabledAt = nil
+		}
+		hook.Active = *input.Active
+	}
+
+	if err := app.store.Webhooks.Update(r.Context(), hook); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusOK, hook); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Delete webhook
+//
+//	@Summary		Delete webhook
+//	@Description	delete a webhook and its delivery log
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID		path	int	true	"user ID"
+//	@Param			webhookID	path	int	true	"webhook ID"
+//	@Security		ApiKeyAuth
+//	@Success		204
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks/{webhookID} [delete]
+func (app *application) deleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := r.Context().Value(webhookCtx).(*store.Webhook)
+
+	if err := app.store.Webhooks.Delete(r.Context(), hook.UserID, hook.ID); err != nil {
+		switch {
+		case errors.Is(err, store.ErrNotFound):
+			app.notFoundResponse(w, r, err)
+		default:
+			app.internalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := app.jsonResponse(w, http.StatusNoContent, nil); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// List webhook deliveries
+//
+//	@Summary		List webhook deliveries
+//	@Description	list the delivery attempts of a webhook, newest first
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID		path	int	true	"user ID"
+//	@Param			webhookID	path	int	true	"webhook ID"
+//	@Param			page		query	int	false	"Current page"
+//	@Param			page_size	query	int	false	"Page size"
+//	@Security		ApiKeyAuth
+//	@Success		200
+//	@Failure		400	{object}	error
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks/{webhookID}/deliveries [get]
+func (app *application) webhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	hook := r.Context().Value(webhookCtx).(*store.Webhook)
+
+	input := store.Filter{
+		Page:     readInt(r, "page", 1),
+		PageSize: readInt(r, "page_size", 20),
+	}
+
+	if err := Validate.Struct(input); err != nil {
+		app.badRequestResponse(w, r, err)
+		return
+	}
+
+	deliveries, metadata, err := app.store.Webhooks.Deliveries(r.Context(), hook.ID, input)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = writeJSON(w, http.StatusOK, envelop{"metadata": metadata, "data": deliveries}); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// Send test event
+//
+//	@Summary		Send test event
+//	@Description	post a single signed test event to the webhook, without retries, and return the logged attempt.
+//	@Description	Disabled webhooks can be tested too, test events never disable a webhook.
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			userID		path	int	true	"user ID"
+//	@Param			webhookID	path	int	true	"webhook ID"
+//	@Security		ApiKeyAuth
+//	@Success		200	{object}	store.WebhookDelivery
+//	@Failure		401	{object}	error
+//	@Failure		403	{object}	error
+//	@Failure		404	{object}	error
+//	@Failure		500	{object}	error
+//	@Router			/users/{userID}/webhooks/{webhookID}/test [post]
+func (app *application) testWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	hook := r.Context().Value(webhookCtx).(*store.Webhook)
+
+	event := webhook.Event{
+		Type:      webhook.EventTest,
+		CreatedAt: time.Now(),
+		Data:      map[string]any{"webhook_id": hook.ID, "base_code": hook.BaseCode, "target_code": hook.TargetCode},
+	}
+
+	sender := *app.webhooks
+	sender.MaxAttempts = 1
+
+	delivery, err := app.deliverWebhook(r.Context(), &sender, hook, event)
+	if err != nil {
+		app.internalServerError(w, r, err)
+		return
+	}
+
+	if err = app.jsonResponse(w, http.StatusOK, delivery); err != nil {
+		app.internalServerError(w, r, err)
+	}
+}
+
+// dispatchWebhooks notifies the webhooks watching the pair of a freshly
+// recorded observation, when it moved by more than their threshold. Each
+// delivery is queued on the delivery queue, where it retries without holding
+// up other background work.
+func (app *application) dispatchWebhooks(ctx context.Context, observation store.RateObservation) {
+	hooks, err := app.store.Webhooks.ListActive(ctx, observation.BaseCode, observation.TargetCode)
+	if err != nil {
+		app.logWebhookError(0, err)
+		return
+	}
+
+	for _, hook := range hooks {
+		var change float64
+		if hook.LastRate != nil && !hook.LastRate.IsZero() {
+			change = percentChange(*hook.LastRate, observation.Rate)
+			if math.Abs(change) <= hook.Threshold {
+				continue
+			}
+		}
+
+		if err = app.store.Webhooks.Advance(ctx, hook.ID, observation.Rate); err != nil {
+			app.logWebhookError(hook.ID, err)
+			continue
+		}
+
+		event := webhook.Event{
+			Type:      webhook.EventRateChanged,
+			CreatedAt: time.Now(),
+			Data: RateChangedData{
+				RateObservation: observation,
+				PreviousRate:    hook.LastRate,
+				ChangePercent:   change,
+			},
+		}
+
+		app.deliveries.enqueue("webhook delivery", func(ctx context.Context) {
+			delivery, err := app.deliverWebhook(ctx, app.webhooks, &hook, event)
+			if err != nil {
+				app.logWebhookError(hook.ID, err)
+				return
+			}
+
+			disabled, err := app.store.Webhooks.RecordOutcome(ctx, hook.ID, delivery.Success,
+				app.config.webhookConfig.disableAfter)
+			if err != nil {
+				app.logWebhookError(hook.ID, err)
+				return
+			}
+
+			if disabled {
+				app.logger.LogAttrs(ctx,
+					slog.LevelWarn,
+					"Webhook disabled after repeated failures",
+					slog.Int64("webhook", hook.ID),
+					slog.String("url", hook.URL),
+				)
+			}
+		})
+	}
+}
+
+// deliverWebhook sends event through sender, logs every attempt and returns the last one
+func (app *application) deliverWebhook(ctx context.Context, sender *webhook.Sender, hook *store.Webhook,
+	event webhook.Event) (*store.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+
+	var last *store.WebhookDelivery
+
+	// The outcome is read from the attempts
+	_ = sender.Deliver(ctx, hook.URL, hook.Secret, event, func(attempt webhook.Attempt) {
+		delivery := &store.WebhookDelivery{
+			WebhookID:   hook.ID,
+			Event:       event.Type,
+			Payload:     payload,
+			Attempt:     attempt.Number,
+			Success:     attempt.Success(),
+			DurationMs:  attempt.Duration.Milliseconds(),
+			DeliveredAt: attempt.At,
+		}
+		if attempt.StatusCode != 0 {
+			delivery.StatusCode = &attempt.StatusCode
+		}
+		if attempt.Err != nil {
+			delivery.Error = attempt.Err.Error()
+		}
+
+		if err := app.store.Webhooks.LogDelivery(ctx, delivery); err != nil {
+			app.logWebhookError(hook.ID, err)
+		}
+
+		last = delivery
+	})
+
+	return last, nil
+}
+
+// validWebhookURL checks the scheme and, unless private receivers are
+// allowed, that the host resolves to public addresses only
+func (app *application) validWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "https" && !(u.Scheme == "http" && app.config.webhookConfig.allowHTTP) {
+		return errInsecureWebhookURL
+	}
+
+	if app.config.webhookConfig.allowPrivate {
+		return nil
+	}
+
+	return webhook.CheckURL(ctx, raw)
+}
+
+func (app *application) logWebhookError(id int64, err error) {
+	app.logger.LogAttrs(context.Background(),
+		slog.LevelError,
+		"Webhook delivery failed",
+		slog.Int64("webhook", id),
+		slog.String("error", err.Error()),
+	)
+}
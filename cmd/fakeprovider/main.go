@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/fakeprovider"
+	"log"
+	"net/http"
+	"time"
+)
+
+type controlInput struct {
+	Latency string              `json:"latency"`
+	Fault   *fakeprovider.Fault `json:"fault"`
+}
+
+// fakeprovider serves exchangerate-api responses from fixtures. Point the API
+// at it with RATE_PROVIDER_URL=http://localhost:8081 and any EXCHANGER_RATE_API key.
+//
+// Faults and latency can be changed at runtime:
+//
+//	curl -X PUT localhost:8081/_control -d '{"latency":"2s","fault":{"status":429,"error_type":"quota-reached"}}'
+//	curl -X DELETE localhost:8081/_control
+func main() {
+	port := flag.Int("port", 8081, "listen port")
+	fixturesPath := flag.String("fixtures", "", "path to a JSON fixtures file, defaults to a built-in USD set")
+	latency := flag.Duration("latency", 0, "delay added to every response")
+	faultStatus := flag.Int("fault-status", 0, "answer every request with this status code")
+	faultType := flag.String("fault-type", "unknown-code", "error-type reported with -fault-status")
+	flag.Parse()
+
+	fixtures := fakeprovider.DefaultFixtures()
+	if *fixturesPath != "" {
+		var err error
+		if fixtures, err = fakeprovider.LoadFixtures(*fixturesPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	srv := fakeprovider.New(fixtures)
+	srv.SetLatency(*latency)
+	if *faultStatus != 0 {
+		srv.SetFault(&fakeprovider.Fault{Status: *faultStatus, ErrorType: *faultType})
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/", srv)
+	mux.HandleFunc("/_control", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			var input controlInput
+			if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			d, err := time.ParseDuration(input.Latency)
+			if input.Latency != "" && err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			srv.SetLatency(d)
+			srv.SetFault(input.Fault)
+		case http.MethodDelete:
+			srv.SetLatency(0)
+			srv.SetFault(nil)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	addr := fmt.Sprintf(":%d", *port)
+	log.Printf("Fake rate provider listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, mux))
+}
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rates
+    DROP COLUMN IF EXISTS sources,
+    DROP COLUMN IF EXISTS spread;
@@ -0,0 +1,3 @@
+ALTER TABLE exchange_rates
+    ADD COLUMN IF NOT EXISTS sources TEXT[]         NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS spread  DECIMAL(18, 8) NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS provider_usage
+(
+    provider VARCHAR(50) NOT NULL,
+    day      DATE        NOT NULL,
+    calls    INT         NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (provider, day)
+);
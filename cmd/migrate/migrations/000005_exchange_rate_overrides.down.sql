@@ -0,0 +1,4 @@
+ALTER TABLE exchange_rates
+    DROP COLUMN IF EXISTS source,
+    DROP COLUMN IF EXISTS set_by,
+    DROP COLUMN IF EXISTS override_expires_at;
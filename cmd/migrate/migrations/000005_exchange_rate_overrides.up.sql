@@ -0,0 +1,4 @@
+ALTER TABLE exchange_rates
+    ADD COLUMN IF NOT EXISTS source              VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS set_by              INT REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS override_expires_at TIMESTAMP;
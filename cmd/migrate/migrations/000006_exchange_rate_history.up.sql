@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS exchange_rate_history
+(
+    id          BIGSERIAL PRIMARY KEY NOT NULL,
+    base_code   VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    target_code VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    rate        DECIMAL(18, 8)        NOT NULL,
+    spread      DECIMAL(18, 8)        NOT NULL DEFAULT 0,
+    sources     TEXT[]                NOT NULL DEFAULT '{}',
+    source      VARCHAR(100)          NOT NULL DEFAULT '',
+    observed_at TIMESTAMP             NOT NULL,
+    recorded_at TIMESTAMP             NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX exchange_rate_history_pair_observed_idx ON exchange_rate_history (base_code, target_code, observed_at);
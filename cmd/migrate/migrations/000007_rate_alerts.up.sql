@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS rate_alerts
+(
+    id                BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id           INT                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    base_code         VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    target_code       VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    kind              VARCHAR(10)           NOT NULL,
+    threshold         DECIMAL(18, 8)        NOT NULL,
+    active            BOOLEAN               NOT NULL DEFAULT TRUE,
+    last_rate         DECIMAL(18, 8),
+    last_triggered_at TIMESTAMP,
+    created_at        TIMESTAMP             NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX rate_alerts_user_idx ON rate_alerts (user_id);
+CREATE INDEX rate_alerts_pair_idx ON rate_alerts (base_code, target_code) WHERE active;
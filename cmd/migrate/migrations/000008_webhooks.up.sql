@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS webhooks
+(
+    id            BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id       INT                   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url           TEXT                  NOT NULL,
+    secret        VARCHAR(64)           NOT NULL,
+    base_code     VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    target_code   VARCHAR(3)            NOT NULL REFERENCES currencies (code) ON DELETE CASCADE,
+    threshold     DECIMAL(18, 8)        NOT NULL DEFAULT 0,
+    last_rate     DECIMAL(18, 8),
+    active        BOOLEAN               NOT NULL DEFAULT TRUE,
+    failure_count INT                   NOT NULL DEFAULT 0,
+    disabled_at   TIMESTAMP,
+    created_at    TIMESTAMP             NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhooks_user_idx ON webhooks (user_id);
+CREATE INDEX webhooks_pair_idx ON webhooks (base_code, target_code) WHERE active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id           BIGSERIAL PRIMARY KEY NOT NULL,
+    webhook_id   BIGINT                NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event        VARCHAR(50)           NOT NULL,
+    payload      JSONB                 NOT NULL,
+    attempt      INT                   NOT NULL,
+    status_code  INT,
+    error        TEXT                  NOT NULL DEFAULT '',
+    success      BOOLEAN               NOT NULL,
+    duration_ms  INT                   NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMP             NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX webhook_deliveries_webhook_idx ON webhook_deliveries (webhook_id, delivered_at);
@@ -0,0 +1,14 @@
+ALTER TABLE transactions
+    DROP COLUMN IF EXISTS side,
+    DROP COLUMN IF EXISTS mid_rate,
+    DROP COLUMN IF EXISTS spread_bps;
+
+DROP TABLE IF EXISTS rate_markups;
+
+ALTER TABLE exchange_rate_history
+    DROP COLUMN IF EXISTS bid,
+    DROP COLUMN IF EXISTS ask;
+
+ALTER TABLE exchange_rates
+    DROP COLUMN IF EXISTS bid,
+    DROP COLUMN IF EXISTS ask;
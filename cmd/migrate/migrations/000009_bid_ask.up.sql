@@ -0,0 +1,32 @@
+ALTER TABLE exchange_rates
+    ADD COLUMN IF NOT EXISTS bid DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ask DECIMAL(18, 8) NOT NULL DEFAULT 0;
+UPDATE exchange_rates SET bid = rate, ask = rate;
+
+ALTER TABLE exchange_rate_history
+    ADD COLUMN IF NOT EXISTS bid DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS ask DECIMAL(18, 8) NOT NULL DEFAULT 0;
+UPDATE exchange_rate_history SET bid = rate, ask = rate;
+
+-- A row without a pair holds the default markup
+CREATE TABLE IF NOT EXISTS rate_markups
+(
+    id          SERIAL PRIMARY KEY NOT NULL,
+    base_code   VARCHAR(3) REFERENCES currencies (code) ON DELETE CASCADE,
+    target_code VARCHAR(3) REFERENCES currencies (code) ON DELETE CASCADE,
+    bid_bps     DECIMAL(10, 4)     NOT NULL DEFAULT 0,
+    ask_bps     DECIMAL(10, 4)     NOT NULL DEFAULT 0,
+    updated_at  TIMESTAMP          NOT NULL DEFAULT NOW(),
+
+    CHECK ((base_code IS NULL) = (target_code IS NULL))
+);
+
+CREATE UNIQUE INDEX rate_markups_pair_idx ON rate_markups (COALESCE(base_code, ''), COALESCE(target_code, ''));
+
+-- Currency codes were declared as integers
+ALTER TABLE transactions
+    ALTER COLUMN base_code TYPE VARCHAR(3) USING base_code::VARCHAR,
+    ALTER COLUMN target_code TYPE VARCHAR(3) USING target_code::VARCHAR,
+    ADD COLUMN IF NOT EXISTS side       VARCHAR(4)     NOT NULL DEFAULT 'mid',
+    ADD COLUMN IF NOT EXISTS mid_rate   DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS spread_bps DECIMAL(10, 4) NOT NULL DEFAULT 0;
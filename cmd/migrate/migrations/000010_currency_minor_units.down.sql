@@ -0,0 +1,2 @@
+ALTER TABLE currencies
+    DROP COLUMN IF EXISTS minor_units;
@@ -0,0 +1,13 @@
+ALTER TABLE currencies
+    ADD COLUMN IF NOT EXISTS minor_units SMALLINT NOT NULL DEFAULT 2 CHECK (minor_units BETWEEN 0 AND 4);
+
+-- ISO 4217 currencies without two decimals
+UPDATE currencies SET minor_units = 0
+WHERE code IN ('BIF', 'CLP', 'DJF', 'GNF', 'ISK', 'JPY', 'KMF', 'KRW', 'PYG', 'RWF', 'UGX', 'UYI', 'VND', 'VUV',
+               'XAF', 'XOF', 'XPF');
+
+UPDATE currencies SET minor_units = 3
+WHERE code IN ('BHD', 'IQD', 'JOD', 'KWD', 'LYD', 'OMR', 'TND');
+
+UPDATE currencies SET minor_units = 4
+WHERE code IN ('CLF', 'UYW');
@@ -0,0 +1,3 @@
+UPDATE exchange_rates
+SET next_update = last_update
+WHERE source = 'manual' AND override_expires_at IS NULL;
@@ -0,0 +1,4 @@
+-- Manual rates without expiry are never due, they used to become stale as soon as they were set
+UPDATE exchange_rates
+SET next_update = '9999-12-31'
+WHERE source = 'manual' AND override_expires_at IS NULL;
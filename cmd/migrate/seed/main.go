@@ -4,6 +4,7 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/minhnghia2k3/exchanger/internal/database"
 	"github.com/minhnghia2k3/exchanger/internal/env"
+	"github.com/minhnghia2k3/exchanger/internal/provider"
 	"github.com/minhnghia2k3/exchanger/internal/store"
 	"log"
 )
@@ -26,5 +27,25 @@ func main() {
 
 	storage := store.NewStorage(db)
 
-	seed(db, storage)
+	// Calls made from here count against the same budgets as the API's
+	budgets, err := provider.ParseBudgets(env.GetString("RATE_PROVIDER_BUDGETS", "exchangerate-api:0:1500"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	rateProvider, err := provider.New(provider.Config{
+		Name:             env.GetString("RATE_PROVIDER", provider.ExchangeRateAPIName),
+		APIKey:           env.GetString("EXCHANGER_RATE_API", ""),
+		BaseURL:          env.GetString("RATE_PROVIDER_URL", ""),
+		Strategy:         env.GetString("RATE_PROVIDER_STRATEGY", provider.StrategyFailover),
+		Tolerance:        env.GetFloat("RATE_CONSENSUS_TOLERANCE", 0.01),
+		BreakerThreshold: env.GetInt("RATE_BREAKER_THRESHOLD", 3),
+		BreakerCooldown:  env.GetString("RATE_BREAKER_COOLDOWN", "30s"),
+		Meter:            provider.NewMeter(storage.Usage, budgets),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	seed(db, storage, rateProvider)
 }
@@ -24,61 +24,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/currencies": {
+        "/admin/markups": {
             "get": {
-                "description": "get all currencies",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "currencies"
-                ],
-                "summary": "List currencies",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Current page",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page size",
-                        "name": "page_size",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Sort",
-                        "name": "sort",
-                        "in": "query"
-                    },
+                "security": [
                     {
-                        "type": "string",
-                        "description": "Search",
-                        "name": "search",
-                        "in": "query"
+                        "ApiKeyAuth": []
                     }
                 ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            },
-            "post": {
-                "description": "add currency detail",
+                "description": "get the default markup and every per-pair markup applied to the mid rate",
                 "consumes": [
                     "application/json"
                 ],
@@ -86,30 +39,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "currencies"
-                ],
-                "summary": "Add currency",
-                "parameters": [
-                    {
-                        "description": "Add currency",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.AddCurrencyInput"
-                        }
-                    }
+                    "Admin"
                 ],
+                "summary": "List markups",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Markup"
+                            }
+                        }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {}
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {}
                     },
                     "500": {
@@ -119,86 +67,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/currencies/{currencyID}": {
-            "get": {
-                "description": "get currency by id",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "currencies"
-                ],
-                "summary": "Get currency",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Currency ID",
-                        "name": "currencyID",
-                        "in": "path"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            },
-            "delete": {
-                "description": "delete currency by id",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "currencies"
-                ],
-                "summary": "Delete currency",
-                "parameters": [
+        "/admin/markups/default": {
+            "put": {
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "currency ID",
-                        "name": "currencyID",
-                        "in": "path",
-                        "required": true
+                        "ApiKeyAuth": []
                     }
                 ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {}
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {}
-                    }
-                }
-            },
-            "patch": {
-                "description": "update currency by id",
+                "description": "set the markup of pairs without their own and reprice them",
                 "consumes": [
                     "application/json"
                 ],
@@ -206,37 +82,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "currencies"
+                    "Admin"
                 ],
-                "summary": "update currency",
+                "summary": "Set default markup",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "currency ID",
-                        "name": "currencyID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "update currency payload",
+                        "description": "Bid and ask markup in basis points",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateCurrencyInput"
+                            "$ref": "#/definitions/main.MarkupInput"
                         }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Markup"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {}
                     },
                     "500": {
@@ -246,14 +122,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/rates/{base}/{target}": {
-            "get": {
+        "/admin/markups/{base}/{target}": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get exchange rate by code",
+                "description": "set the markup of one pair, overriding the default, and reprice it",
                 "consumes": [
                     "application/json"
                 ],
@@ -261,9 +137,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exchange Rates"
+                    "Admin"
                 ],
-                "summary": "Get exchange rate",
+                "summary": "Set pair markup",
                 "parameters": [
                     {
                         "type": "string",
@@ -278,13 +154,22 @@ const docTemplate = `{
                         "name": "target",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Bid and ask markup in basis points",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.MarkupInput"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ExchangeRate"
+                            "$ref": "#/definitions/store.Markup"
                         }
                     },
                     "400": {
@@ -295,8 +180,8 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {}
                     },
                     "500": {
@@ -305,13 +190,13 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "add exchange rate by code",
+                "description": "remove the markup of one pair so it falls back to the default",
                 "consumes": [
                     "application/json"
                 ],
@@ -319,9 +204,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exchange Rates"
+                    "Admin"
                 ],
-                "summary": "Add exchange rate",
+                "summary": "Remove pair markup",
                 "parameters": [
                     {
                         "type": "string",
@@ -339,11 +224,8 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/store.ExchangeRate"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -366,14 +248,16 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/admin/providers": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "update exchange rate conversion",
+                "description": "get the configured rate provider and the state of its circuit breakers",
                 "consumes": [
                     "application/json"
                 ],
@@ -381,36 +265,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Exchange Rates"
-                ],
-                "summary": "Update exchange rate conversion",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Base currency code",
-                        "name": "base",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Target currency code",
-                        "name": "target",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Admin"
                 ],
+                "summary": "Provider status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/store.ExchangeRate"
+                            "$ref": "#/definitions/main.ProviderStatus"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {}
-                    },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {}
@@ -419,10 +283,6 @@ const docTemplate = `{
                         "description": "Forbidden",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
@@ -430,9 +290,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/tokens/activate": {
-            "put": {
-                "description": "Activate user by invitation token",
+        "/admin/providers/usage": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the upstream calls made today and this month against each provider budget",
                 "consumes": [
                     "application/json"
                 ],
@@ -440,30 +305,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "Admin"
                 ],
-                "summary": "Activate user account",
-                "parameters": [
-                    {
-                        "description": "Invitation token",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
+                "summary": "Provider usage",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.ActivationUserInvitations"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/provider.UsageReport"
+                            }
                         }
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {}
                     },
                     "500": {
@@ -473,9 +333,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/tokens/authentication": {
+        "/admin/rates/{base}/latest": {
             "post": {
-                "description": "login user account and generate access token",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "fetch every rate of a base currency from the provider and upsert them in one transaction",
                 "consumes": [
                     "application/json"
                 ],
@@ -483,25 +348,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "Admin"
                 ],
-                "summary": "Login user account",
+                "summary": "Ingest latest rates",
                 "parameters": [
                     {
-                        "description": "Login payload",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.LoginPayload"
-                        }
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/main.LoginResponse"
+                            "$ref": "#/definitions/main.IngestResult"
                         }
                     },
                     "400": {
@@ -512,16 +375,24 @@ const docTemplate = `{
                         "description": "Unauthorized",
                         "schema": {}
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {}
                     }
                 }
             }
         },
-        "/tokens/refresh": {
-            "post": {
-                "description": "Refresh access token and refresh token",
+        "/currencies": {
+            "get": {
+                "description": "get all currencies",
                 "consumes": [
                     "application/json"
                 ],
@@ -529,45 +400,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tokens"
+                    "currencies"
                 ],
-                "summary": "Refresh token",
+                "summary": "List currencies",
                 "parameters": [
                     {
-                        "description": "Refresh token",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/main.RefreshPayload"
-                        }
+                        "type": "integer",
+                        "description": "Current page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search",
+                        "name": "search",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/main.LoginResponse"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
-            }
-        },
-        "/users": {
+            },
             "post": {
-                "description": "register user",
+                "description": "add currency detail",
                 "consumes": [
                     "application/json"
                 ],
@@ -575,35 +452,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "currencies"
                 ],
-                "summary": "Register user",
+                "summary": "Add currency",
                 "parameters": [
                     {
-                        "description": "Register user payload",
+                        "description": "Add currency",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.RegisterUserPayload"
+                            "$ref": "#/definitions/main.AddCurrencyInput"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/main.UserWithToken"
-                        }
+                    "200": {
+                        "description": "OK"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
                     "409": {
                         "description": "Conflict",
                         "schema": {}
@@ -615,14 +485,47 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userID}": {
+        "/currencies/{currencyID}": {
             "get": {
-                "security": [
+                "description": "get currency by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currencies"
+                ],
+                "summary": "Get currency",
+                "parameters": [
                     {
-                        "ApiKeyAuth": []
+                        "type": "integer",
+                        "description": "Currency ID",
+                        "name": "currencyID",
+                        "in": "path"
                     }
                 ],
-                "description": "get user by id",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete currency by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -630,35 +533,28 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "currencies"
                 ],
-                "summary": "Get user",
+                "summary": "Delete currency",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "user ID",
-                        "name": "userID",
+                        "description": "currency ID",
+                        "name": "currencyID",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/store.User"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {}
-                    },
-                    "404": {
-                        "description": "Not Found",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {}
                     },
                     "500": {
@@ -667,8 +563,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "delete user by id",
+            "patch": {
+                "description": "update currency by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -676,16 +572,25 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "currencies"
                 ],
-                "summary": "Delete user",
+                "summary": "update currency",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "user ID",
-                        "name": "userID",
+                        "description": "currency ID",
+                        "name": "currencyID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "update currency payload",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateCurrencyInput"
+                        }
                     }
                 ],
                 "responses": {
@@ -706,59 +611,2369 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "main.ActivationUserInvitations": {
-            "type": "object",
-            "properties": {
-                "token": {
-                    "type": "string"
-                }
-            }
-        },
-        "main.AddCurrencyInput": {
-            "type": "object",
-            "required": [
-                "code",
-                "name"
-            ],
-            "properties": {
-                "code": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "symbol_url": {
-                    "type": "string"
-                }
-            }
         },
-        "main.LoginPayload": {
-            "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string"
-                },
-                "password": {
-                    "type": "string",
+        "/exchanges/pair/{base}/{target}/{amount}": {
+            "get": {
+                "description": "convert an amount at the current rate, or at the rate in effect at a given time.\nPairs that are not stored are derived from their inverse or through other stored pairs,\nfor past conversions through the pairs as they were at that time.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchanges"
+                ],
+                "summary": "exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Amount to convert",
+                        "name": "amount",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Convert at the rate in effect at this time (RFC 3339)",
+                        "name": "at",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "buy",
+                            "sell",
+                            "mid"
+                        ],
+                        "type": "string",
+                        "description": "Customer side on the base currency",
+                        "name": "side",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "half_up",
+                            "half_even",
+                            "floor",
+                            "ceiling"
+                        ],
+                        "type": "string",
+                        "description": "Rounding of the result, defaults to the configured mode",
+                        "name": "rounding",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ExchangeResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    },
+                    "503": {
+                        "description": "the rate is stale and the deployment rejects stale rates",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates": {
+            "get": {
+                "description": "list the stored pairs with optional base, target and staleness filters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "List exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only stale (true) or fresh (false) pairs",
+                        "name": "stale",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Current page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "last_update",
+                            "-last_update",
+                            "rate",
+                            "-rate"
+                        ],
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "stream the current rates as CSV, or every observation between from and to when either is set",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Export exchange rates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "base,target,rate,bid,ask,as_of,source",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "load rates from CSV (base,target,rate,as_of with an optional header) or JSON lines in one transaction.\nNothing is written when any row is invalid, the response lists the rejected rows.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin"
+                ],
+                "summary": "Import exchange rates",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/main.ImportResult"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/matrix": {
+            "get": {
+                "description": "convert between every pair of the given currencies, deriving pairs that are not stored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Rate matrix",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated currency codes",
+                        "name": "codes",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RateMatrix"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/stream": {
+            "get": {
+                "description": "push a server-sent event whenever a subscribed pair is written. Comment lines are sent\nas heartbeats. Reconnecting with Last-Event-ID replays the events missed meanwhile, events\nrecorded shortly before it may be sent again. Only rates written by this instance are pushed\nlive, rates written by the ingest job or other instances are delivered on reconnect.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Stream rate updates",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Comma separated pairs, e.g. USD-EUR,USD-JPY",
+                        "name": "pairs",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{target}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get exchange rate by code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Get exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "add exchange rate by code",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Add exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.ExchangeRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update exchange rate conversion",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Update exchange rate conversion",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.RateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{target}/candles": {
+            "get": {
+                "description": "open, high, low and close of a pair per interval, computed from the rate history",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Exchange rate candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "1h",
+                            "1d",
+                            "1w"
+                        ],
+                        "type": "string",
+                        "description": "Candle interval",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of candles, latest first",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Candle"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{target}/history": {
+            "get": {
+                "description": "list every recorded rate of a pair, newest first by default",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Exchange rate history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Current page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{target}/override": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "set a manual rate that automatic refreshes will not overwrite until it expires",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Override exchange rate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Manual rate",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.OverrideRateInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ExchangeRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "expire the manual rate so the next refresh fetches the pair from the provider",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Remove exchange rate override",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.ExchangeRate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/rates/{base}/{target}/stats": {
+            "get": {
+                "description": "change, range, volatility and moving averages of a pair over a window of its rate history.\nThe window defaults to the 30 days before to, which defaults to now.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Exchange Rates"
+                ],
+                "summary": "Exchange rate statistics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base currency code",
+                        "name": "base",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target currency code",
+                        "name": "target",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Observations spanned by the moving averages",
+                        "name": "period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/analytics.Stats"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tokens/activate": {
+            "put": {
+                "description": "Activate user by invitation token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Activate user account",
+                "parameters": [
+                    {
+                        "description": "Invitation token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ActivationUserInvitations"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tokens/authentication": {
+            "post": {
+                "description": "login user account and generate access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Login user account",
+                "parameters": [
+                    {
+                        "description": "Login payload",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.LoginPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/tokens/refresh": {
+            "post": {
+                "description": "Refresh access token and refresh token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Refresh token",
+                "parameters": [
+                    {
+                        "description": "Refresh token",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RefreshPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users": {
+            "post": {
+                "description": "register user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register user",
+                "parameters": [
+                    {
+                        "description": "Register user payload",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RegisterUserPayload"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.UserWithToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "delete user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/alerts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "list the rate alerts of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "List alerts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Alert"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "email the user when a rate crosses a threshold or moves by more than a percentage in 24 hours",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Create alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Alert",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAlertInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/store.Alert"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/alerts/{alertID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get a rate alert of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Get alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "alert ID",
+                        "name": "alertID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Alert"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete a rate alert of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Delete alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "alert ID",
+                        "name": "alertID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "change the condition of a rate alert, or pause and resume it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "alerts"
+                ],
+                "summary": "Update alert",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "alert ID",
+                        "name": "alertID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed fields",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateAlertInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Alert"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "list the webhooks of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/store.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "post a signed rate.changed event to url whenever the pair moves by more than threshold percent",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Create webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateWebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.WebhookWithSecret"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/webhooks/{webhookID}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get a webhook of a user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Get webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Webhook"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete a webhook and its delivery log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Delete webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "change the url or threshold of a webhook, or disable and re-enable it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Update webhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed fields",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateWebhookInput"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/webhooks/{webhookID}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "list the delivery attempts of a webhook, newest first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "List webhook deliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Current page",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/users/{userID}/webhooks/{webhookID}/test": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "post a single signed test event to the webhook, without retries, and return the logged attempt.\nDisabled webhooks can be tested too, test events never disable a webhook.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Send test event",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "user ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "webhook ID",
+                        "name": "webhookID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/store.WebhookDelivery"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "analytics.Stats": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "Change is Last - First, ChangePercent relates it to First",
+                    "type": "number"
+                },
+                "change_percent": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "ema": {
+                    "type": "number"
+                },
+                "first": {
+                    "type": "number"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "last": {
+                    "type": "number"
+                },
+                "max": {
+                    "type": "number"
+                },
+                "max_at": {
+                    "type": "string"
+                },
+                "min": {
+                    "type": "number"
+                },
+                "min_at": {
+                    "type": "string"
+                },
+                "period": {
+                    "description": "Period is the number of observations the moving averages span",
+                    "type": "integer"
+                },
+                "returns_std_dev": {
+                    "description": "ReturnsStdDev is the sample standard deviation of the simple returns\nbetween consecutive observations, in percent. Nil below three observations.",
+                    "type": "number"
+                },
+                "sma": {
+                    "description": "SMA averages the last Period rates, EMA weights them with 2/(Period+1).\nBoth are nil when the window holds fewer than Period observations.",
+                    "type": "number"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "decimal.RoundingMode": {
+            "type": "string",
+            "enum": [
+                "half_up",
+                "half_even",
+                "floor",
+                "ceiling"
+            ],
+            "x-enum-varnames": [
+                "HalfUp",
+                "HalfEven",
+                "Floor",
+                "Ceiling"
+            ]
+        },
+        "main.ActivationUserInvitations": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.AddCurrencyInput": {
+            "type": "object",
+            "required": [
+                "code",
+                "name"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "minor_units": {
+                    "description": "MinorUnits defaults to the ISO 4217 value of the code",
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 0
+                },
+                "name": {
+                    "type": "string"
+                },
+                "symbol_url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateAlertInput": {
+            "type": "object",
+            "required": [
+                "base_code",
+                "kind",
+                "target_code",
+                "threshold"
+            ],
+            "properties": {
+                "base_code": {
+                    "type": "string"
+                },
+                "kind": {
+                    "description": "Kind is cross, to fire when the rate crosses Threshold, or change, to\nfire when it moves by more than Threshold percent in 24 hours",
+                    "type": "string",
+                    "enum": [
+                        "cross",
+                        "change"
+                    ]
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.CreateWebhookInput": {
+            "type": "object",
+            "required": [
+                "base_code",
+                "target_code",
+                "url"
+            ],
+            "properties": {
+                "base_code": {
+                    "type": "string"
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "description": "Threshold is the move in percent since the last notification that triggers the next one",
+                    "type": "number",
+                    "minimum": 0
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ExchangeResult": {
+            "type": "object",
+            "properties": {
+                "age_seconds": {
+                    "description": "AgeSeconds is the time since the rate was observed",
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "number"
+                },
+                "base_code": {
+                    "type": "string"
+                },
+                "is_stale": {
+                    "description": "IsStale is set once the rate is past its next scheduled update",
+                    "type": "boolean"
+                },
+                "mid_rate": {
+                    "type": "number"
+                },
+                "minor_units": {
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "Path lists the currencies the conversion went through, base first",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "provenance": {
+                    "description": "Provenance tells whether the rate is stored, inverted or chained through other pairs",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/rategraph.Provenance"
+                        }
+                    ]
+                },
+                "rate": {
+                    "description": "Rate is the ask when buying, the bid when selling and MidRate otherwise",
+                    "type": "number"
+                },
+                "rate_time": {
+                    "description": "RateTime is when the applied rate was observed, the oldest leg for derived rates",
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result is UnroundedResult rounded to the minor units of the target currency",
+                    "type": "number"
+                },
+                "rounding": {
+                    "$ref": "#/definitions/decimal.RoundingMode"
+                },
+                "side": {
+                    "description": "Side is buy or sell for the customer buying or selling the base currency, mid for the interbank rate",
+                    "type": "string"
+                },
+                "spread_bps": {
+                    "description": "SpreadBps is how far Rate is from MidRate, in basis points",
+                    "type": "number"
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "unrounded_result": {
+                    "description": "UnroundedResult is the exact product of Amount and Rate",
+                    "type": "number"
+                }
+            }
+        },
+        "main.ImportError": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "row": {
+                    "description": "Row is the line of the row in the uploaded file",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.ImportResult": {
+            "type": "object",
+            "properties": {
+                "current": {
+                    "description": "Current is the number of pairs whose current rate changed, older rows only fill in history",
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.ImportError"
+                    }
+                },
+                "imported": {
+                    "description": "Imported is the number of rows recorded, zero when any row is rejected",
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.IngestResult": {
+            "type": "object",
+            "properties": {
+                "base_code": {
+                    "type": "string"
+                },
+                "fetched": {
+                    "type": "integer"
+                },
+                "written": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.LoginPayload": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
                     "maxLength": 72,
                     "minLength": 8
                 }
             }
         },
-        "main.LoginResponse": {
+        "main.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.MarkupInput": {
+            "type": "object",
+            "properties": {
+                "ask_bps": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "bid_bps": {
+                    "type": "number",
+                    "minimum": 0
+                }
+            }
+        },
+        "main.MatrixCell": {
+            "type": "object",
+            "properties": {
+                "last_update": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "provenance": {
+                    "$ref": "#/definitions/rategraph.Provenance"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.OverrideRateInput": {
+            "type": "object",
+            "required": [
+                "rate"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.ProviderStatus": {
+            "type": "object",
+            "properties": {
+                "breakers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/provider.BreakerStatus"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.RateMatrix": {
+            "type": "object",
+            "properties": {
+                "codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rates": {
+                    "description": "Rates[i][j] converts Codes[i] into Codes[j], null when no path exists",
+                    "type": "array",
+                    "items": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/main.MatrixCell"
+                        }
+                    }
+                }
+            }
+        },
+        "main.RateResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
+                "age_seconds": {
+                    "description": "AgeSeconds is the time since the rate was observed",
+                    "type": "integer"
+                },
+                "ask": {
+                    "type": "number"
+                },
+                "base_code": {
                     "type": "string"
                 },
-                "refresh_token": {
+                "bid": {
+                    "description": "Bid and Ask are the mid Rate moved by the pair's markup",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_stale": {
+                    "description": "IsStale is set once the rate is past its next scheduled update",
+                    "type": "boolean"
+                },
+                "last_update": {
+                    "type": "string"
+                },
+                "next_update": {
+                    "type": "string"
+                },
+                "override_expires_at": {
+                    "description": "OverrideExpiresAt ends a manual rate, nil keeps it until removed",
+                    "type": "string"
+                },
+                "rate": {
+                    "type": "number"
+                },
+                "set_by": {
+                    "description": "SetBy is the user who set a manual rate",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Source lists the providers that answered, comma separated, or is SourceManual",
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "spread": {
+                    "type": "number"
+                },
+                "target_code": {
                     "type": "string"
                 }
             }
@@ -794,12 +3009,35 @@ const docTemplate = `{
                 }
             }
         },
+        "main.UpdateAlertInput": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "cross",
+                        "change"
+                    ]
+                },
+                "threshold": {
+                    "type": "number"
+                }
+            }
+        },
         "main.UpdateCurrencyInput": {
             "type": "object",
             "properties": {
                 "code": {
                     "type": "string"
                 },
+                "minor_units": {
+                    "type": "integer",
+                    "maximum": 4,
+                    "minimum": 0
+                },
                 "name": {
                     "type": "string"
                 },
@@ -808,6 +3046,22 @@ const docTemplate = `{
                 }
             }
         },
+        "main.UpdateWebhookInput": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active set to true re-enables a webhook disabled after repeated failures",
+                    "type": "boolean"
+                },
+                "threshold": {
+                    "type": "number",
+                    "minimum": 0
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "main.UserWithToken": {
             "type": "object",
             "properties": {
@@ -840,12 +3094,199 @@ const docTemplate = `{
                 }
             }
         },
+        "main.WebhookWithSecret": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "base_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "failure_count": {
+                    "description": "FailureCount counts the deliveries that failed in a row",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_rate": {
+                    "description": "LastRate is the rate of the last notification",
+                    "type": "number"
+                },
+                "secret": {
+                    "type": "string"
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "provider.BreakerState": {
+            "type": "string",
+            "enum": [
+                "closed",
+                "open",
+                "half-open"
+            ],
+            "x-enum-varnames": [
+                "StateClosed",
+                "StateOpen",
+                "StateHalfOpen"
+            ]
+        },
+        "provider.BreakerStatus": {
+            "type": "object",
+            "properties": {
+                "failures": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "opened_at": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/provider.BreakerState"
+                }
+            }
+        },
+        "provider.Budget": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "type": "integer"
+                },
+                "monthly": {
+                    "type": "integer"
+                }
+            }
+        },
+        "provider.UsageReport": {
+            "type": "object",
+            "properties": {
+                "budget": {
+                    "$ref": "#/definitions/provider.Budget"
+                },
+                "daily": {
+                    "type": "integer"
+                },
+                "exhausted": {
+                    "description": "Exhausted is true when either limit has been reached",
+                    "type": "boolean"
+                },
+                "monthly": {
+                    "type": "integer"
+                },
+                "provider": {
+                    "type": "string"
+                }
+            }
+        },
+        "rategraph.Provenance": {
+            "type": "string",
+            "enum": [
+                "direct",
+                "inverse",
+                "derived"
+            ],
+            "x-enum-varnames": [
+                "Direct",
+                "Inverse",
+                "Derived"
+            ]
+        },
+        "store.Alert": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "base_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "last_rate": {
+                    "description": "LastRate is the rate seen when the alert was last evaluated",
+                    "type": "number"
+                },
+                "last_triggered_at": {
+                    "type": "string"
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.Candle": {
+            "type": "object",
+            "properties": {
+                "close": {
+                    "type": "number"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "high": {
+                    "type": "number"
+                },
+                "low": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "time": {
+                    "type": "string"
+                }
+            }
+        },
         "store.ExchangeRate": {
             "type": "object",
             "properties": {
+                "ask": {
+                    "type": "number"
+                },
                 "base_code": {
                     "type": "string"
                 },
+                "bid": {
+                    "description": "Bid and Ask are the mid Rate moved by the pair's markup",
+                    "type": "number"
+                },
                 "id": {
                     "type": "integer"
                 },
@@ -855,11 +3296,52 @@ const docTemplate = `{
                 "next_update": {
                     "type": "string"
                 },
+                "override_expires_at": {
+                    "description": "OverrideExpiresAt ends a manual rate, nil keeps it until removed",
+                    "type": "string"
+                },
                 "rate": {
                     "type": "number"
                 },
+                "set_by": {
+                    "description": "SetBy is the user who set a manual rate",
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Source lists the providers that answered, comma separated, or is SourceManual",
+                    "type": "string"
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "spread": {
+                    "type": "number"
+                },
+                "target_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "store.Markup": {
+            "type": "object",
+            "properties": {
+                "ask_bps": {
+                    "type": "number"
+                },
+                "base_code": {
+                    "type": "string"
+                },
+                "bid_bps": {
+                    "type": "number"
+                },
                 "target_code": {
                     "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
@@ -908,6 +3390,84 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "store.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "base_code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled_at": {
+                    "type": "string"
+                },
+                "failure_count": {
+                    "description": "FailureCount counts the deliveries that failed in a row",
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_rate": {
+                    "description": "LastRate is the rate of the last notification",
+                    "type": "number"
+                },
+                "target_code": {
+                    "type": "string"
+                },
+                "threshold": {
+                    "type": "number"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "store.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "delivered_at": {
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "success": {
+                    "type": "boolean"
+                },
+                "webhook_id": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,139 @@
+// Package analytics computes change and volatility figures over a rate series.
+package analytics
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+var ErrNoData = errors.New("no observations in the window")
+
+// Point is one observed rate
+type Point struct {
+	Time time.Time
+	Rate float64
+}
+
+type Stats struct {
+	Count int       `json:"count"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	First float64   `json:"first"`
+	Last  float64   `json:"last"`
+	// Change is Last - First, ChangePercent relates it to First
+	Change        float64   `json:"change"`
+	ChangePercent float64   `json:"change_percent"`
+	Min           float64   `json:"min"`
+	MinAt         time.Time `json:"min_at"`
+	Max           float64   `json:"max"`
+	MaxAt         time.Time `json:"max_at"`
+	// ReturnsStdDev is the sample standard deviation of the simple returns
+	// between consecutive observations, in percent. Nil below three observations.
+	ReturnsStdDev *float64 `json:"returns_std_dev"`
+	// Period is the number of observations the moving averages span
+	Period int `json:"period"`
+	// SMA averages the last Period rates, EMA weights them with 2/(Period+1).
+	// Both are nil when the window holds fewer than Period observations.
+	SMA *float64 `json:"sma"`
+	EMA *float64 `json:"ema"`
+}
+
+// Summarize computes Stats over points sorted oldest first
+func Summarize(points []Point, period int) (*Stats, error) {
+	if len(points) == 0 {
+		return nil, ErrNoData
+	}
+
+	first, last := points[0], points[len(points)-1]
+
+	stats := &Stats{
+		Count:  len(points),
+		From:   first.Time,
+		To:     last.Time,
+		First:  first.Rate,
+		Last:   last.Rate,
+		Change: last.Rate - first.Rate,
+		Min:    first.Rate,
+		MinAt:  first.Time,
+		Max:    first.Rate,
+		MaxAt:  first.Time,
+		Period: period,
+	}
+
+	if first.Rate != 0 {
+		stats.ChangePercent = stats.Change / first.Rate * 100
+	}
+
+	for _, p := range points[1:] {
+		if p.Rate < stats.Min {
+			stats.Min, stats.MinAt = p.Rate, p.Time
+		}
+		if p.Rate > stats.Max {
+			stats.Max, stats.MaxAt = p.Rate, p.Time
+		}
+	}
+
+	stats.ReturnsStdDev = returnsStdDev(points)
+
+	if period > 0 && len(points) >= period {
+		sma := SMA(points, period)
+		ema := EMA(points, period)
+		stats.SMA, stats.EMA = &sma, &ema
+	}
+
+	return stats, nil
+}
+
+func returnsStdDev(points []Point) *float64 {
+	returns := make([]float64, 0, len(points)-1)
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Rate == 0 {
+			continue
+		}
+		returns = append(returns, (points[i].Rate/points[i-1].Rate-1)*100)
+	}
+
+	if len(returns) < 2 {
+		return nil
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	stddev := math.Sqrt(variance)
+
+	return &stddev
+}
+
+// SMA averages the rates of the last period points, len(points) must be at least period
+func SMA(points []Point, period int) float64 {
+	var sum float64
+	for _, p := range points[len(points)-period:] {
+		sum += p.Rate
+	}
+
+	return sum / float64(period)
+}
+
+// EMA is seeded with the average of the first period points and then
+// updated with every later point, len(points) must be at least period
+func EMA(points []Point, period int) float64 {
+	alpha := 2 / float64(period+1)
+	ema := SMA(points[:period], period)
+
+	for _, p := range points[period:] {
+		ema = alpha*p.Rate + (1-alpha)*ema
+	}
+
+	return ema
+}
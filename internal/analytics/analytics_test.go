@@ -0,0 +1,55 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func series(rates ...float64) []Point {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	points := make([]Point, len(rates))
+	for i, rate := range rates {
+		points[i] = Point{Time: start.Add(time.Duration(i) * time.Hour), Rate: rate}
+	}
+
+	return points
+}
+
+func TestSummarize(t *testing.T) {
+	points := series(100, 110, 99, 108.9)
+
+	stats, err := Summarize(points, 3)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, stats.Count)
+	assert.Equal(t, points[0].Time, stats.From)
+	assert.Equal(t, points[3].Time, stats.To)
+	assert.InDelta(t, 8.9, stats.Change, 1e-9)
+	assert.InDelta(t, 8.9, stats.ChangePercent, 1e-9)
+	assert.Equal(t, 99.0, stats.Min)
+	assert.Equal(t, points[2].Time, stats.MinAt)
+	assert.Equal(t, 110.0, stats.Max)
+	assert.Equal(t, points[1].Time, stats.MaxAt)
+
+	// Returns are +10%, -10%, +10%: mean 3.33, sample variance 133.33
+	assert.InDelta(t, 11.547005, *stats.ReturnsStdDev, 1e-6)
+
+	assert.InDelta(t, (110+99+108.9)/3.0, *stats.SMA, 1e-9)
+	// Seeded with (100+110+99)/3 = 103, then 0.5*108.9 + 0.5*103
+	assert.InDelta(t, 105.95, *stats.EMA, 1e-9)
+}
+
+func TestSummarize_ShortWindow(t *testing.T) {
+	stats, err := Summarize(series(1.5, 1.6), 20)
+	assert.NoError(t, err)
+
+	assert.Nil(t, stats.ReturnsStdDev)
+	assert.Nil(t, stats.SMA)
+	assert.Nil(t, stats.EMA)
+
+	_, err = Summarize(nil, 20)
+	assert.ErrorIs(t, err, ErrNoData)
+}
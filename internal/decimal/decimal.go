@@ -0,0 +1,389 @@
+// Package decimal implements exact base-10 numbers for rates and amounts,
+// so that values read from DECIMAL columns reach customers and the ledger
+// without binary floating point rounding.
+package decimal
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale is the number of fractional digits of the DECIMAL(18, 8) columns rates and amounts are stored in
+const Scale = 8
+
+// DivisionScale is the number of fractional digits kept by Div when deriving
+// rates, beyond Scale so that chained conversions do not compound rounding
+const DivisionScale = 16
+
+// maxExponent bounds the exponent Parse accepts, so that input such as
+// "1e999999999" cannot make it allocate and compute a huge power of ten
+const maxExponent = 32
+
+var (
+	ErrSyntax       = errors.New("invalid decimal")
+	ErrRoundingMode = errors.New("rounding mode must be half_up, half_even, floor or ceiling")
+)
+
+// RoundingMode decides which way a value between two representable ones goes
+type RoundingMode string
+
+const (
+	// HalfUp rounds to the nearest value, ties away from zero
+	HalfUp RoundingMode = "half_up"
+	// HalfEven rounds to the nearest value, ties to the even neighbour (banker's rounding)
+	HalfEven RoundingMode = "half_even"
+	// Floor rounds towards negative infinity
+	Floor RoundingMode = "floor"
+	// Ceiling rounds towards positive infinity
+	Ceiling RoundingMode = "ceiling"
+)
+
+// ParseRoundingMode reads a rounding mode, accepting half-up as well as half_up
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	mode := RoundingMode(strings.ReplaceAll(strings.ToLower(s), "-", "_"))
+
+	switch mode {
+	case HalfUp, HalfEven, Floor, Ceiling:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrRoundingMode, s)
+	}
+}
+
+var (
+	Zero = Decimal{}
+	One  = NewFromInt(1)
+)
+
+var ten = big.NewInt(10)
+
+// Decimal is the exact number coef * 10^-scale. The zero value is 0 and
+// values are immutable, every operation returns a new Decimal.
+type Decimal struct {
+	coef  *big.Int
+	scale int32
+}
+
+// New returns unscaled * 10^-scale
+func New(unscaled int64, scale int32) Decimal {
+	if scale < 0 {
+		return Decimal{coef: new(big.Int).Mul(big.NewInt(unscaled), pow10(-scale))}
+	}
+
+	return Decimal{coef: big.NewInt(unscaled), scale: scale}
+}
+
+func NewFromInt(value int64) Decimal {
+	return New(value, 0)
+}
+
+// NewFromFloat returns the shortest decimal that reads back as value, which is
+// the literal a float parsed from JSON or a config was written as, rounded
+// half up to Scale when it has more fractional digits
+func NewFromFloat(value float64) Decimal {
+	d, err := ParseRound(strconv.FormatFloat(value, 'f', -1, 64), Scale)
+	if err != nil {
+		// NaN and infinities have no decimal form
+		return Zero
+	}
+
+	return d
+}
+
+// Parse reads a plain or exponent notation number such as "-12.5" or "1e-3".
+// Exponents beyond ±32 and more than DivisionScale fractional digits are rejected.
+func Parse(s string) (Decimal, error) {
+	d, err := parse(s)
+	if err != nil {
+		return Zero, err
+	}
+
+	if d.scale > DivisionScale {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+
+	return d, nil
+}
+
+// ParseRound is Parse for literals that may carry more fractional digits than
+// DivisionScale, such as upstream quotes, which are rounded half up to places
+func ParseRound(s string, places int32) (Decimal, error) {
+	d, err := parse(s)
+	if err != nil {
+		return Zero, err
+	}
+
+	if d.scale > places {
+		return d.Round(places), nil
+	}
+
+	return d, nil
+}
+
+// parse reads s with any number of fractional digits, the exponent is bounded
+func parse(s string) (Decimal, error) {
+	mantissa, exponent := s, int64(0)
+
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.ParseInt(s[i+1:], 10, 32)
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+		}
+		mantissa, exponent = s[:i], exp
+	}
+
+	digits, fraction, _ := strings.Cut(mantissa, ".")
+	digits += fraction
+
+	if digits == "" || digits == "-" || digits == "+" || strings.ContainsAny(digits[1:], "+-") {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+
+	scale := int64(len(fraction)) - exponent
+	if scale > math.MaxInt32 {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	if scale < 0 {
+		coef.Mul(coef, pow10(int32(-scale)))
+		scale = 0
+	}
+
+	return Decimal{coef: coef, scale: int32(scale)}, nil
+}
+
+// MustParse is Parse for constants, it panics on invalid input
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+func (d Decimal) value() *big.Int {
+	if d.coef == nil {
+		return new(big.Int)
+	}
+
+	return d.coef
+}
+
+// rescale returns the coefficient of d at a scale not lower than its own
+func (d Decimal) rescale(scale int32) *big.Int {
+	coef := new(big.Int).Set(d.value())
+	if scale > d.scale {
+		coef.Mul(coef, pow10(scale-d.scale))
+	}
+
+	return coef
+}
+
+func (d Decimal) Add(other Decimal) Decimal {
+	scale := max(d.scale, other.scale)
+
+	return Decimal{coef: new(big.Int).Add(d.rescale(scale), other.rescale(scale)), scale: scale}
+}
+
+func (d Decimal) Sub(other Decimal) Decimal {
+	return d.Add(other.Neg())
+}
+
+// Mul is exact, the scale of the product is the sum of both scales
+func (d Decimal) Mul(other Decimal) Decimal {
+	return Decimal{coef: new(big.Int).Mul(d.value(), other.value()), scale: d.scale + other.scale}
+}
+
+// Div returns d / other rounded half away from zero to scale fractional digits.
+// It panics when other is zero.
+func (d Decimal) Div(other Decimal, scale int32) Decimal {
+	if other.IsZero() {
+		panic("decimal: division by zero")
+	}
+
+	// d / other * 10^scale = d.coef * 10^(scale + other.scale - d.scale) / other.coef
+	num := new(big.Int).Set(d.value())
+	den := new(big.Int).Set(other.value())
+
+	if shift := scale + other.scale - d.scale; shift >= 0 {
+		num.Mul(num, pow10(shift))
+	} else {
+		den.Mul(den, pow10(-shift))
+	}
+
+	return Decimal{coef: quoRound(num, den, HalfUp), scale: scale}
+}
+
+// Round returns d rounded half away from zero to places fractional digits,
+// padding with zeros when d has fewer. It matches how Postgres rounds into a
+// DECIMAL column.
+func (d Decimal) Round(places int32) Decimal {
+	return d.RoundWith(places, HalfUp)
+}
+
+// RoundWith returns d rounded with mode to places fractional digits, padding
+// with zeros when d has fewer
+func (d Decimal) RoundWith(places int32, mode RoundingMode) Decimal {
+	if places >= d.scale {
+		return Decimal{coef: d.rescale(places), scale: places}
+	}
+
+	return Decimal{coef: quoRound(d.value(), pow10(d.scale-places), mode), scale: places}
+}
+
+// quoRound divides num by den rounding with mode
+func quoRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	// QuoRem truncates towards zero, away moves the quotient one step from it
+	negative := num.Sign() != den.Sign()
+	half := new(big.Int).Abs(new(big.Int).Lsh(rem, 1)).CmpAbs(den)
+
+	var away bool
+	switch mode {
+	case HalfEven:
+		away = half > 0 || (half == 0 && quo.Bit(0) == 1)
+	case Floor:
+		away = negative
+	case Ceiling:
+		away = !negative
+	default:
+		away = half >= 0
+	}
+
+	if away {
+		if negative {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	return quo
+}
+
+func (d Decimal) Neg() Decimal {
+	return Decimal{coef: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	return Decimal{coef: new(big.Int).Abs(d.value()), scale: d.scale}
+}
+
+// Cmp returns -1, 0 or +1 as d is less than, equal to or greater than other
+func (d Decimal) Cmp(other Decimal) int {
+	scale := max(d.scale, other.scale)
+
+	return d.rescale(scale).Cmp(other.rescale(scale))
+}
+
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+func (d Decimal) Sign() int {
+	return d.value().Sign()
+}
+
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Float64 returns the nearest float, for statistics where exactness does not matter
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+
+	return f
+}
+
+// String returns d in plain notation with exactly its scale of fractional digits
+func (d Decimal) String() string {
+	digits := new(big.Int).Abs(d.value()).String()
+
+	if d.scale > 0 {
+		if pad := int(d.scale) + 1 - len(digits); pad > 0 {
+			digits = strings.Repeat("0", pad) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+
+	if d.Sign() < 0 {
+		return "-" + digits
+	}
+
+	return digits
+}
+
+// MarshalJSON encodes d as a string so JSON clients do not parse it into a float
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(d.String())), nil
+}
+
+// UnmarshalJSON accepts a string or a number, numbers are read from their
+// literal and never go through a float
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	text := string(data)
+	if text == "null" {
+		return nil
+	}
+
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+
+	parsed, err := Parse(text)
+	if err != nil {
+		return err
+	}
+
+	*d = parsed
+
+	return nil
+}
+
+// Scan implements sql.Scanner for DECIMAL columns, NULL scans as zero
+func (d *Decimal) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Zero
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		parsed, err := Parse(v)
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	case int64:
+		*d = NewFromInt(v)
+	case float64:
+		*d = NewFromFloat(v)
+	default:
+		return fmt.Errorf("decimal: cannot scan %T", src)
+	}
+
+	return nil
+}
+
+// Value implements driver.Valuer, the text form is what Postgres expects for DECIMAL
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+}
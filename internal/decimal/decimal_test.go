@@ -0,0 +1,162 @@
+package decimal
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cases := map[string]string{
+		"0":           "0",
+		"1.10000000":  "1.10000000",
+		"-0.5":        "-0.5",
+		"+12.25":      "12.25",
+		".75":         "0.75",
+		"1e-3":        "0.001",
+		"2.5E2":       "250",
+		"0.000000001": "0.000000001",
+		"1e32":        "100000000000000000000000000000000",
+		"1e-16":       "0.0000000000000001",
+	}
+
+	for input, want := range cases {
+		d, err := Parse(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, d.String(), input)
+	}
+
+	for _, input := range []string{"", ".", "-", "1.2.3", "1-2", "abc", "1e", "1_000",
+		"1e33", "1e-33", "1e999999999", "1e-999999999", "1e-17", "0.00000000000000001"} {
+		_, err := Parse(input)
+		assert.ErrorIs(t, err, ErrSyntax, input)
+	}
+}
+
+func TestParseRound(t *testing.T) {
+	d, err := ParseRound("0.006191950464396285", DivisionScale)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.0061919504643963", d.String())
+
+	d, err = ParseRound("1.5", DivisionScale)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5", d.String())
+
+	_, err = ParseRound("1e-999999999", DivisionScale)
+	assert.ErrorIs(t, err, ErrSyntax)
+}
+
+func TestNewFromFloat(t *testing.T) {
+	assert.Equal(t, "0.92", NewFromFloat(0.92).String())
+	// 17 and more fractional digits are rounded rather than dropped
+	assert.Equal(t, "0.00619195", NewFromFloat(1/161.5).String())
+	assert.Equal(t, "0.00000001", NewFromFloat(0.0000000123456789012).String())
+	assert.True(t, NewFromFloat(math.NaN()).IsZero())
+}
+
+func TestArithmetic(t *testing.T) {
+	// The classic float failure is exact here
+	assert.Equal(t, "0.3", MustParse("0.1").Add(MustParse("0.2")).String())
+	assert.Equal(t, "-0.15", MustParse("0.1").Sub(MustParse("0.25")).String())
+	assert.Equal(t, "1234.5678000000", MustParse("1000").Mul(MustParse("1.2345678")).Round(10).String())
+	assert.Equal(t, "0.00666667", One.Div(MustParse("150"), 8).String())
+	assert.Equal(t, "-0.33333333", NewFromInt(-1).Div(MustParse("3"), 8).String())
+	assert.Equal(t, "-0.66666667", NewFromInt(-2).Div(MustParse("3"), 8).String())
+	assert.Panics(t, func() { One.Div(Zero, 8) })
+}
+
+func TestRound(t *testing.T) {
+	assert.Equal(t, "2.35", MustParse("2.345").Round(2).String())
+	assert.Equal(t, "-2.35", MustParse("-2.345").Round(2).String())
+	assert.Equal(t, "2.34", MustParse("2.3449").Round(2).String())
+	assert.Equal(t, "10.50", MustParse("10.5").Round(2).String())
+	assert.Equal(t, "3", MustParse("2.5").Round(0).String())
+}
+
+func TestRoundWith(t *testing.T) {
+	tests := []struct {
+		value  string
+		places int32
+		mode   RoundingMode
+		want   string
+	}{
+		{"2.5", 0, HalfUp, "3"},
+		{"2.5", 0, HalfEven, "2"},
+		{"3.5", 0, HalfEven, "4"},
+		{"-2.5", 0, HalfEven, "-2"},
+		{"2.51", 0, HalfEven, "3"},
+		{"1.005", 2, HalfUp, "1.01"},
+		{"1.005", 2, HalfEven, "1.00"},
+		{"1.239", 2, Floor, "1.23"},
+		{"-1.231", 2, Floor, "-1.24"},
+		{"1.231", 2, Ceiling, "1.24"},
+		{"-1.239", 2, Ceiling, "-1.23"},
+		{"1.2", 3, Floor, "1.200"},
+		{"150.4999", 0, HalfUp, "150"},
+	}
+
+	for _, tc := range tests {
+		got := MustParse(tc.value).RoundWith(tc.places, tc.mode)
+		assert.Equal(t, tc.want, got.String(), "%s %s to %d", tc.value, tc.mode, tc.places)
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	mode, err := ParseRoundingMode("half-even")
+	assert.NoError(t, err)
+	assert.Equal(t, HalfEven, mode)
+
+	mode, err = ParseRoundingMode("Ceiling")
+	assert.NoError(t, err)
+	assert.Equal(t, Ceiling, mode)
+
+	_, err = ParseRoundingMode("up")
+	assert.ErrorIs(t, err, ErrRoundingMode)
+}
+
+func TestCmp(t *testing.T) {
+	assert.Equal(t, 0, MustParse("1.50").Cmp(MustParse("1.5")))
+	assert.Equal(t, -1, MustParse("-2").Cmp(MustParse("1.5")))
+	assert.Equal(t, 1, MustParse("0.000001").Cmp(Zero))
+	assert.True(t, Zero.IsZero())
+	assert.Equal(t, 1.25, MustParse("1.25").Float64())
+}
+
+func TestJSON(t *testing.T) {
+	var v struct {
+		Rate   Decimal `json:"rate"`
+		Amount Decimal `json:"amount"`
+	}
+
+	err := json.Unmarshal([]byte(`{"rate": 0.91234567, "amount": "100.10"}`), &v)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.91234567", v.Rate.String())
+	assert.Equal(t, "100.10", v.Amount.String())
+
+	out, err := json.Marshal(v)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"rate": "0.91234567", "amount": "100.10"}`, string(out))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"rate": "abc"}`), &v))
+}
+
+func TestScan(t *testing.T) {
+	var d Decimal
+
+	assert.NoError(t, d.Scan([]byte("0.91000000")))
+	assert.Equal(t, "0.91000000", d.String())
+
+	assert.NoError(t, d.Scan(int64(7)))
+	assert.Equal(t, "7", d.String())
+
+	assert.NoError(t, d.Scan(nil))
+	assert.True(t, d.IsZero())
+
+	assert.Error(t, d.Scan(true))
+
+	value, err := MustParse("1.5").Value()
+	assert.NoError(t, err)
+	assert.Equal(t, "1.5", value)
+}
@@ -26,3 +26,31 @@ func GetInt(key string, fallback int) int {
 
 	return valInt
 }
+
+func GetFloat(key string, fallback float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	valFloat, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return valFloat
+}
+
+func GetBool(key string, fallback bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	valBool, err := strconv.ParseBool(val)
+	if err != nil {
+		return fallback
+	}
+
+	return valBool
+}
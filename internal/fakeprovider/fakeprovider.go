@@ -0,0 +1,308 @@
+// Package fakeprovider serves the exchangerate-api v6 response shapes from
+// local fixtures, so the API and the seeders can run without network access.
+package fakeprovider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fixtures describes the rates served by the fake. Rates are expressed
+// against Base, every other pair is derived from them.
+type Fixtures struct {
+	Base           string             `json:"base"`
+	Rates          map[string]float64 `json:"rates"`
+	Names          map[string]string  `json:"names"`
+	UpdateInterval string             `json:"update_interval"`
+}
+
+// Fault makes the fake answer with an exchangerate-api error. Count limits
+// the number of failing requests, 0 fails every request until cleared.
+type Fault struct {
+	Status    int    `json:"status"`
+	ErrorType string `json:"error_type"`
+	Count     int    `json:"count"`
+}
+
+type Server struct {
+	mu       sync.Mutex
+	fixtures Fixtures
+	latency  time.Duration
+	fault    *Fault
+	requests int
+	now      func() time.Time
+}
+
+type response struct {
+	Result             string             `json:"result"`
+	ErrorType          string             `json:"error-type,omitempty"`
+	TimeLastUpdateUnix int64              `json:"time_last_update_unix,omitempty"`
+	TimeLastUpdateUtc  string             `json:"time_last_update_utc,omitempty"`
+	TimeNextUpdateUnix int64              `json:"time_next_update_unix,omitempty"`
+	TimeNextUpdateUtc  string             `json:"time_next_update_utc,omitempty"`
+	BaseCode           string             `json:"base_code,omitempty"`
+	TargetCode         string             `json:"target_code,omitempty"`
+	ConversionRate     float64            `json:"conversion_rate,omitempty"`
+	ConversionRates    map[string]float64 `json:"conversion_rates,omitempty"`
+	SupportedCodes     [][]string         `json:"supported_codes,omitempty"`
+}
+
+func New(fixtures Fixtures) *Server {
+	return &Server{
+		fixtures: fixtures,
+		now:      time.Now,
+	}
+}
+
+// NewTestServer starts an in-process fake, close it with the returned server's Close
+func NewTestServer(fixtures Fixtures) (*Server, *httptest.Server) {
+	s := New(fixtures)
+
+	return s, httptest.NewServer(s)
+}
+
+// LoadFixtures reads fixtures from a JSON file
+func LoadFixtures(path string) (Fixtures, error) {
+	var fixtures Fixtures
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fixtures, err
+	}
+
+	err = json.Unmarshal(data, &fixtures)
+
+	return fixtures, err
+}
+
+// DefaultFixtures returns a small set of USD based rates
+func DefaultFixtures() Fixtures {
+	return Fixtures{
+		Base: "USD",
+		Rates: map[string]float64{
+			"USD": 1,
+			"EUR": 0.92,
+			"GBP": 0.79,
+			"JPY": 150.25,
+			"VND": 25450,
+		},
+		Names: map[string]string{
+			"USD": "United States Dollar",
+			"EUR": "Euro",
+			"GBP": "Pound Sterling",
+			"JPY": "Japanese Yen",
+			"VND": "Vietnamese Đồng",
+		},
+		UpdateInterval: "24h",
+	}
+}
+
+func (s *Server) SetLatency(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latency = latency
+}
+
+// SetFault injects an error response, nil clears it. The fault is copied so
+// that requests counting it down leave the caller's value intact.
+func (s *Server) SetFault(fault *Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault != nil {
+		copied := *fault
+		fault = &copied
+	}
+	s.fault = fault
+}
+
+// ExhaustQuota answers every request with the quota-reached error
+func (s *Server) ExhaustQuota() {
+	s.SetFault(&Fault{Status: http.StatusTooManyRequests, ErrorType: "quota-reached"})
+}
+
+func (s *Server) SetRate(code string, rate float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fixtures.Rates == nil {
+		s.fixtures.Rates = make(map[string]float64)
+	}
+	s.fixtures.Rates[code] = rate
+}
+
+// Requests returns the number of API requests served so far
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Paths follow /{key}/{endpoint}/...
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	s.mu.Lock()
+	s.requests++
+	latency := s.latency
+	fault := s.fault
+	if fault != nil && fault.Count > 0 {
+		fault.Count--
+		if fault.Count == 0 {
+			s.fault = nil
+		}
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if fault != nil {
+		writeError(w, fault.Status, fault.ErrorType)
+		return
+	}
+
+	if len(parts) < 2 || parts[0] == "" {
+		writeError(w, http.StatusForbidden, "invalid-key")
+		return
+	}
+
+	switch {
+	case parts[1] == "codes" && len(parts) == 2:
+		s.codes(w, r)
+	case parts[1] == "pair" && len(parts) == 4:
+		s.pair(w, r, parts[2], parts[3])
+	case parts[1] == "latest" && len(parts) == 3:
+		s.latest(w, r, parts[2])
+	default:
+		writeError(w, http.StatusNotFound, "malformed-request")
+	}
+}
+
+func (s *Server) codes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	codes := make([][]string, 0, len(s.fixtures.Rates))
+	for code := range s.fixtures.Rates {
+		name, ok := s.fixtures.Names[code]
+		if !ok {
+			name = code
+		}
+		codes = append(codes, []string{code, name})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i][0] < codes[j][0]
+	})
+
+	writeSuccess(w, r, response{Result: "success", SupportedCodes: codes})
+}
+
+func (s *Server) pair(w http.ResponseWriter, r *http.Request, base, target string) {
+	s.mu.Lock()
+	baseRate, okBase := s.fixtures.Rates[base]
+	targetRate, okTarget := s.fixtures.Rates[target]
+	s.mu.Unlock()
+
+	if !okBase || !okTarget || baseRate == 0 {
+		writeError(w, http.StatusNotFound, "unsupported-code")
+		return
+	}
+
+	resp := s.timestamps()
+	resp.BaseCode = base
+	resp.TargetCode = target
+	resp.ConversionRate = targetRate / baseRate
+
+	writeSuccess(w, r, resp)
+}
+
+func (s *Server) latest(w http.ResponseWriter, r *http.Request, base string) {
+	s.mu.Lock()
+	baseRate, ok := s.fixtures.Rates[base]
+	rates := make(map[string]float64, len(s.fixtures.Rates))
+	for code, rate := range s.fixtures.Rates {
+		if ok && baseRate != 0 {
+			rates[code] = rate / baseRate
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok || baseRate == 0 {
+		writeError(w, http.StatusNotFound, "unsupported-code")
+		return
+	}
+
+	resp := s.timestamps()
+	resp.BaseCode = base
+	resp.ConversionRates = rates
+
+	writeSuccess(w, r, resp)
+}
+
+// timestamps mimics the daily update window of the real API
+func (s *Server) timestamps() response {
+	interval, err := time.ParseDuration(s.fixtures.UpdateInterval)
+	if err != nil || interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	last := s.now().UTC().Truncate(interval)
+	next := last.Add(interval)
+
+	return response{
+		Result:             "success",
+		TimeLastUpdateUnix: last.Unix(),
+		TimeLastUpdateUtc:  last.Format(time.RFC1123Z),
+		TimeNextUpdateUnix: next.Unix(),
+		TimeNextUpdateUtc:  next.Format(time.RFC1123Z),
+	}
+}
+
+// writeSuccess sets an ETag and honours If-None-Match like a caching upstream
+func writeSuccess(w http.ResponseWriter, r *http.Request, data response) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "unknown-code")
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:8]) + `"`
+
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, status int, errorType string) {
+	writeJSON(w, status, response{Result: "error", ErrorType: errorType})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(data)
+}
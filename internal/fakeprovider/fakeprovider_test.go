@@ -0,0 +1,81 @@
+package fakeprovider
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/provider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_ExchangeRateAPI(t *testing.T) {
+	fake, srv := NewTestServer(DefaultFixtures())
+	defer srv.Close()
+
+	fake.now = func() time.Time { return time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC) }
+
+	client := provider.NewExchangeRateAPI("key", srv.URL)
+	ctx := context.Background()
+
+	rate, err := client.GetPair(ctx, "EUR", "JPY")
+	assert.NoError(t, err)
+	assert.InDelta(t, 150.25/0.92, rate.Rate.Float64(), 1e-9)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), rate.LastUpdate)
+	assert.Equal(t, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), rate.NextUpdate)
+
+	rates, err := client.GetLatest(ctx, "USD")
+	assert.NoError(t, err)
+	assert.Len(t, rates, 4)
+
+	codes, err := client.GetCodes(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, provider.Currency{Code: "EUR", Name: "Euro"}, codes[0])
+
+	_, err = client.GetPair(ctx, "USD", "XXX")
+	assert.ErrorIs(t, err, provider.ErrUnsupportedCode)
+
+	assert.Equal(t, 4, fake.Requests())
+}
+
+func TestServer_SetRate(t *testing.T) {
+	fake, srv := NewTestServer(Fixtures{})
+	defer srv.Close()
+
+	fake.SetRate("USD", 1)
+	fake.SetRate("EUR", 0.9)
+
+	rate, err := provider.NewExchangeRateAPI("key", srv.URL).GetPair(context.Background(), "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.9", rate.Rate.String())
+}
+
+func TestServer_Faults(t *testing.T) {
+	fake, srv := NewTestServer(DefaultFixtures())
+	defer srv.Close()
+
+	client := provider.NewExchangeRateAPI("key", srv.URL)
+	ctx := context.Background()
+
+	fake.ExhaustQuota()
+	_, err := client.GetPair(ctx, "USD", "EUR")
+	assert.ErrorIs(t, err, provider.ErrRateLimited)
+
+	// A counted fault clears itself and can be reused
+	fault := &Fault{Status: http.StatusInternalServerError, ErrorType: "unknown", Count: 1}
+	for range 2 {
+		fake.SetFault(fault)
+		_, err = client.GetPair(ctx, "USD", "EUR")
+		assert.Error(t, err)
+		_, err = client.GetPair(ctx, "USD", "EUR")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, fault.Count)
+
+	fake.SetLatency(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, err = client.GetPair(ctx, "USD", "EUR")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,171 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type BreakerState string
+
+const (
+	StateClosed   BreakerState = "closed"
+	StateOpen     BreakerState = "open"
+	StateHalfOpen BreakerState = "half-open"
+)
+
+// BreakerReporter is implemented by providers that guard upstreams with circuit breakers
+type BreakerReporter interface {
+	Breakers() []BreakerStatus
+}
+
+type BreakerStatus struct {
+	Provider  string       `json:"provider"`
+	State     BreakerState `json:"state"`
+	Failures  int          `json:"failures"`
+	OpenedAt  *time.Time   `json:"opened_at,omitempty"`
+	LastError string       `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker wraps a provider and stops calling it after threshold
+// consecutive failures. Once cooldown has elapsed a single trial call is let
+// through (half-open), its outcome closes or re-opens the circuit. A rate
+// limited response opens the circuit immediately.
+type CircuitBreaker struct {
+	provider  RateProvider
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	trial     bool
+	lastError string
+}
+
+func NewCircuitBreaker(provider RateProvider, threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	return &CircuitBreaker{
+		provider:  provider,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     StateClosed,
+	}
+}
+
+func (b *CircuitBreaker) Name() string {
+	return b.provider.Name()
+}
+
+func (b *CircuitBreaker) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	return guard(b, func() (*Rate, error) {
+		return b.provider.GetPair(ctx, base, target)
+	})
+}
+
+func (b *CircuitBreaker) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	return guard(b, func() ([]Rate, error) {
+		return b.provider.GetLatest(ctx, base)
+	})
+}
+
+func (b *CircuitBreaker) GetCodes(ctx context.Context) ([]Currency, error) {
+	return guard(b, func() ([]Currency, error) {
+		return b.provider.GetCodes(ctx)
+	})
+}
+
+func (b *CircuitBreaker) Breakers() []BreakerStatus {
+	return []BreakerStatus{b.Status()}
+}
+
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Provider:  b.provider.Name(),
+		State:     b.state,
+		Failures:  b.failures,
+		LastError: b.lastError,
+	}
+	if b.state != StateClosed {
+		openedAt := b.openedAt
+		status.OpenedAt = &openedAt
+	}
+
+	return status
+}
+
+func guard[T any](b *CircuitBreaker, call func() (T, error)) (T, error) {
+	if !b.allow() {
+		var zero T
+		return zero, fmt.Errorf("%w: %s", ErrCircuitOpen, b.provider.Name())
+	}
+
+	result, err := call()
+	b.record(err)
+
+	return result, err
+}
+
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = StateHalfOpen
+		b.trial = true
+		return true
+	case StateHalfOpen:
+		// Only one trial call at a time
+		if b.trial {
+			return false
+		}
+		b.trial = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	// The call never left or the caller gave up, the upstream is not to blame
+	if errors.Is(err, ErrBudgetExhausted) || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	// The upstream answered, an unsupported code is not its fault
+	if err == nil || errors.Is(err, ErrUnsupportedCode) {
+		b.state = StateClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	b.lastError = err.Error()
+
+	if b.state == StateHalfOpen || b.failures >= b.threshold || errors.Is(err, ErrRateLimited) {
+		b.state = StateOpen
+		b.openedAt = b.now()
+	}
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type flakyProvider struct {
+	staticProvider
+	calls int
+}
+
+func (p *flakyProvider) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	p.calls++
+	return p.staticProvider.GetPair(ctx, base, target)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	upstream := &flakyProvider{staticProvider: staticProvider{name: "a", err: errors.New("down")}}
+	b := NewCircuitBreaker(upstream, 2, time.Minute)
+
+	now := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+
+	ctx := context.Background()
+
+	// Closed: failures are passed through until the threshold is reached
+	_, err := b.GetPair(ctx, "USD", "EUR")
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, StateClosed, b.Status().State)
+
+	_, _ = b.GetPair(ctx, "USD", "EUR")
+	assert.Equal(t, StateOpen, b.Status().State)
+
+	// Open: upstream is not called
+	_, err = b.GetPair(ctx, "USD", "EUR")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, upstream.calls)
+
+	// Half-open: a failing trial re-opens the circuit
+	now = now.Add(time.Minute)
+	_, err = b.GetPair(ctx, "USD", "EUR")
+	assert.NotErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 3, upstream.calls)
+	assert.Equal(t, StateOpen, b.Status().State)
+
+	// Half-open: a successful trial closes the circuit
+	now = now.Add(time.Minute)
+	upstream.err = nil
+	upstream.rate = 1.1
+	rate, err := b.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.1", rate.Rate.String())
+	assert.Equal(t, BreakerStatus{Provider: "a", State: StateClosed, LastError: "down"}, b.Status())
+}
+
+func TestCircuitBreaker_RateLimited(t *testing.T) {
+	b := NewCircuitBreaker(staticProvider{name: "a", err: ErrRateLimited}, 5, time.Minute)
+
+	_, err := b.GetPair(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, StateOpen, b.Status().State)
+}
+
+func TestCircuitBreaker_UnsupportedCode(t *testing.T) {
+	b := NewCircuitBreaker(staticProvider{name: "a", err: ErrUnsupportedCode}, 1, time.Minute)
+
+	_, err := b.GetPair(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, ErrUnsupportedCode)
+	assert.Equal(t, StateClosed, b.Status().State)
+}
+
+func TestCircuitBreaker_Canceled(t *testing.T) {
+	b := NewCircuitBreaker(staticProvider{name: "a", err: context.Canceled}, 1, time.Minute)
+
+	_, err := b.GetPair(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, StateClosed, b.Status().State)
+	assert.Equal(t, 0, b.Status().Failures)
+}
+
+func TestFailover_GetPair(t *testing.T) {
+	primary := NewCircuitBreaker(staticProvider{name: "a", err: errors.New("down")}, 1, time.Minute)
+	secondary := NewCircuitBreaker(staticProvider{name: "b", rate: 2}, 1, time.Minute)
+
+	p := NewFailover(primary, secondary)
+
+	rate, err := p.GetPair(context.Background(), "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", rate.Rate.String())
+	assert.Equal(t, []string{"b"}, rate.Sources)
+	assert.Equal(t, "b", rate.ExchangeRate().Source)
+
+	breakers := p.Breakers()
+	assert.Len(t, breakers, 2)
+	assert.Equal(t, StateOpen, breakers[0].State)
+	assert.Equal(t, StateClosed, breakers[1].State)
+
+	// Every provider failing is reported as a joined error
+	p = NewFailover(primary)
+	_, err = p.GetPair(context.Background(), "USD", "EUR")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// revalidateAfter is how long a response is kept once the upstream confirmed
+// it is unchanged, or when its update window is already over
+const revalidateAfter = time.Minute
+
+// responseCache stores upstream responses until the provider's next update.
+// Expired entries are revalidated with If-None-Match / If-Modified-Since
+// when the upstream sent an ETag or Last-Modified header.
+type responseCache struct {
+	provider string
+	logger   *slog.Logger
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+type cachedResponse struct {
+	body         []byte
+	status       int
+	etag         string
+	lastModified string
+	expires      time.Time
+}
+
+func newResponseCache(provider string, logger *slog.Logger) *responseCache {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return &responseCache{
+		provider: provider,
+		logger:   logger,
+		now:      time.Now,
+		entries:  make(map[string]*cachedResponse),
+	}
+}
+
+// fetch GETs url, key identifies the request in the cache and in logs. expiry
+// returns until when a body may be served, a zero time disables caching it.
+// A nil cache always goes upstream.
+func (c *responseCache) fetch(ctx context.Context, client *http.Client, key, url string,
+	expiry func(body []byte) time.Time) ([]byte, int, error) {
+	var entry *cachedResponse
+	if c != nil {
+		c.mu.Lock()
+		entry = c.entries[key]
+		c.mu.Unlock()
+
+		if entry != nil && c.now().Before(entry.expires) {
+			c.log(ctx, key, "hit")
+			return entry.body, entry.status, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if entry != nil {
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry.lastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		if entry == nil {
+			return nil, resp.StatusCode, fmt.Errorf("%w: unexpected status code %d", errUpstream, resp.StatusCode)
+		}
+
+		c.mu.Lock()
+		entry.expires = c.now().Add(revalidateAfter)
+		c.mu.Unlock()
+
+		c.log(ctx, key, "revalidated")
+		return entry.body, entry.status, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+
+	if c == nil {
+		return body, resp.StatusCode, nil
+	}
+	c.log(ctx, key, "miss")
+
+	if resp.StatusCode > 299 {
+		return body, resp.StatusCode, nil
+	}
+
+	expires := expiry(body)
+	if expires.IsZero() {
+		return body, resp.StatusCode, nil
+	}
+	if !expires.After(c.now()) {
+		expires = c.now().Add(revalidateAfter)
+	}
+
+	c.mu.Lock()
+	c.entries[key] = &cachedResponse{
+		body:         body,
+		status:       resp.StatusCode,
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		expires:      expires,
+	}
+	c.mu.Unlock()
+
+	return body, resp.StatusCode, nil
+}
+
+func (c *responseCache) log(ctx context.Context, key, decision string) {
+	c.logger.LogAttrs(ctx,
+		slog.LevelDebug,
+		"Provider cache",
+		slog.String("provider", c.provider),
+		slog.String("request", key),
+		slog.String("decision", decision),
+	)
+}
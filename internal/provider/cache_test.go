@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/fakeprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRateAPI_Cache(t *testing.T) {
+	fake, srv := fakeprovider.NewTestServer(fakeprovider.DefaultFixtures())
+	defer srv.Close()
+
+	p := NewExchangeRateAPI("key", srv.URL)
+	p.cache = newResponseCache(ExchangeRateAPIName, nil)
+
+	ctx := context.Background()
+
+	// Miss, then served from the cache until the next update
+	first, err := p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	second, err := p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, fake.Requests())
+
+	// Another request is cached separately
+	_, err = p.GetPair(ctx, "USD", "JPY")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.Requests())
+
+	// Past the update window the entry is revalidated with its ETag
+	p.cache.now = func() time.Time { return first.NextUpdate.Add(time.Second) }
+	third, err := p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, first.Rate, third.Rate)
+	assert.Equal(t, 3, fake.Requests())
+
+	// A changed upstream replaces the entry
+	p.cache.now = func() time.Time { return first.NextUpdate.Add(time.Hour) }
+	fake.SetRate("EUR", 0.95)
+	fourth, err := p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "0.95", fourth.Rate.String())
+}
+
+func TestExchangeRateAPI_CacheSkipsErrors(t *testing.T) {
+	fake, srv := fakeprovider.NewTestServer(fakeprovider.DefaultFixtures())
+	defer srv.Close()
+
+	p := NewExchangeRateAPI("key", srv.URL)
+	p.cache = newResponseCache(ExchangeRateAPIName, nil)
+
+	ctx := context.Background()
+
+	fake.ExhaustQuota()
+	_, err := p.GetPair(ctx, "USD", "EUR")
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	fake.SetFault(nil)
+	_, err = p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, fake.Requests())
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+)
+
+const ConsensusName = "consensus"
+
+var ErrNoConsensus = errors.New("providers did not agree on a rate")
+
+var half = decimal.MustParse("0.5")
+
+// Consensus asks every provider for a rate and returns the median of the
+// quotes that lie within tolerance of the overall median. Tolerance is
+// relative, 0.01 drops quotes more than 1% away from the median.
+type Consensus struct {
+	providers []RateProvider
+	tolerance decimal.Decimal
+}
+
+func NewConsensus(tolerance float64, providers ...RateProvider) *Consensus {
+	return &Consensus{
+		providers: providers,
+		tolerance: decimal.NewFromFloat(tolerance),
+	}
+}
+
+func (p *Consensus) Name() string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+
+	return fmt.Sprintf("%s(%s)", ConsensusName, strings.Join(names, ","))
+}
+
+func (p *Consensus) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	quotes := make([]*Rate, len(p.providers))
+	errs := make([]error, len(p.providers))
+
+	var wg sync.WaitGroup
+	for i, provider := range p.providers {
+		wg.Add(1)
+		go func(i int, provider RateProvider) {
+			defer wg.Done()
+
+			rate, err := provider.GetPair(ctx, base, target)
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", provider.Name(), err)
+				return
+			}
+			rate.Sources = []string{provider.Name()}
+			quotes[i] = rate
+		}(i, provider)
+	}
+	wg.Wait()
+
+	var rates []Rate
+	for _, quote := range quotes {
+		if quote != nil {
+			rates = append(rates, *quote)
+		}
+	}
+
+	if len(rates) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return p.agree(rates)
+}
+
+func (p *Consensus) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	byTarget := make(map[string][]Rate)
+	var errs []error
+
+	for _, provider := range p.providers {
+		rates, err := provider.GetLatest(ctx, base)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		for _, rate := range rates {
+			rate.Sources = []string{provider.Name()}
+			byTarget[rate.TargetCode] = append(byTarget[rate.TargetCode], rate)
+		}
+	}
+
+	if len(byTarget) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	rates := make([]Rate, 0, len(byTarget))
+	for _, quotes := range byTarget {
+		rate, err := p.agree(quotes)
+		if err != nil {
+			continue
+		}
+		rates = append(rates, *rate)
+	}
+
+	return rates, nil
+}
+
+// GetCodes returns every currency supported by at least one provider
+func (p *Consensus) GetCodes(ctx context.Context) ([]Currency, error) {
+	seen := make(map[string]bool)
+	var currencies []Currency
+	var errs []error
+
+	for _, provider := range p.providers {
+		codes, err := provider.GetCodes(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+
+		for _, code := range codes {
+			if seen[code.Code] {
+				continue
+			}
+			seen[code.Code] = true
+			currencies = append(currencies, code)
+		}
+	}
+
+	if len(currencies) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return currencies, nil
+}
+
+func (p *Consensus) Breakers() []BreakerStatus {
+	return collectBreakers(p.providers)
+}
+
+// agree rejects outliers and combines the remaining quotes of a single pair
+func (p *Consensus) agree(quotes []Rate) (*Rate, error) {
+	values := make([]decimal.Decimal, 0, len(quotes))
+	for _, quote := range quotes {
+		values = append(values, quote.Rate)
+	}
+	mid := median(values)
+
+	var accepted []Rate
+	for _, quote := range quotes {
+		if !mid.IsZero() && deviation(quote.Rate, mid).Cmp(p.tolerance) <= 0 {
+			accepted = append(accepted, quote)
+		}
+	}
+
+	if len(accepted) == 0 {
+		return nil, fmt.Errorf("%w: %s/%s", ErrNoConsensus, quotes[0].BaseCode, quotes[0].TargetCode)
+	}
+
+	values = values[:0]
+	result := accepted[0]
+	result.Sources = nil
+	low, high := accepted[0].Rate, accepted[0].Rate
+
+	for _, quote := range accepted {
+		values = append(values, quote.Rate)
+		result.Sources = append(result.Sources, quote.Sources...)
+		if quote.Rate.Cmp(low) < 0 {
+			low = quote.Rate
+		}
+		if quote.Rate.Cmp(high) > 0 {
+			high = quote.Rate
+		}
+
+		// Keep the freshest observation and the earliest scheduled refresh
+		if quote.LastUpdate.After(result.LastUpdate) {
+			result.LastUpdate = quote.LastUpdate
+		}
+		if quote.NextUpdate.Before(result.NextUpdate) {
+			result.NextUpdate = quote.NextUpdate
+		}
+	}
+
+	result.Rate = median(values)
+	if !result.Rate.IsZero() {
+		result.Spread = high.Sub(low).Div(result.Rate, decimal.DivisionScale).Float64()
+	}
+	sort.Strings(result.Sources)
+
+	return &result, nil
+}
+
+// median is exact, the mean of the two middle values has at most one more digit
+func median(values []decimal.Decimal) decimal.Decimal {
+	sorted := append([]decimal.Decimal(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Cmp(sorted[j]) < 0
+	})
+
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+
+	return sorted[n/2-1].Add(sorted[n/2]).Mul(half)
+}
+
+// deviation returns |value - reference| / reference
+func deviation(value, reference decimal.Decimal) decimal.Decimal {
+	return value.Sub(reference).Abs().Div(reference, decimal.DivisionScale)
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticProvider struct {
+	name string
+	rate float64
+	err  error
+}
+
+func (p staticProvider) Name() string { return p.name }
+
+func (p staticProvider) GetPair(_ context.Context, base, target string) (*Rate, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Rate{BaseCode: base, TargetCode: target, Rate: decimal.NewFromFloat(p.rate), NextUpdate: time.Unix(100, 0)}, nil
+}
+
+func (p staticProvider) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	rate, err := p.GetPair(ctx, base, "EUR")
+	if err != nil {
+		return nil, err
+	}
+	return []Rate{*rate}, nil
+}
+
+func (p staticProvider) GetCodes(_ context.Context) ([]Currency, error) {
+	return []Currency{{Code: "USD", Name: p.name}, {Code: p.name, Name: p.name}}, p.err
+}
+
+func TestConsensus_GetPair(t *testing.T) {
+	tests := []struct {
+		name            string
+		providers       []RateProvider
+		expectedRate    string
+		expectedSources []string
+		expectedSpread  float64
+		expectedError   error
+	}{
+		{
+			name: "should drop the outlier",
+			providers: []RateProvider{
+				staticProvider{name: "a", rate: 1.00},
+				staticProvider{name: "b", rate: 1.01},
+				staticProvider{name: "c", rate: 1.50},
+			},
+			expectedRate:    "1.005",
+			expectedSources: []string{"a", "b"},
+			expectedSpread:  0.01 / 1.005,
+		},
+		{
+			name: "should ignore failing providers",
+			providers: []RateProvider{
+				staticProvider{name: "a", rate: 2},
+				staticProvider{name: "b", err: errors.New("down")},
+			},
+			expectedRate:    "2",
+			expectedSources: []string{"a"},
+		},
+		{
+			name: "should average the middle quotes exactly",
+			providers: []RateProvider{
+				staticProvider{name: "a", rate: 0.00619195},
+				staticProvider{name: "b", rate: 0.00619196},
+			},
+			expectedRate:    "0.006191955",
+			expectedSources: []string{"a", "b"},
+			expectedSpread:  0.00000001 / 0.006191955,
+		},
+		{
+			name: "should fail when providers disagree",
+			providers: []RateProvider{
+				staticProvider{name: "a", rate: 1},
+				staticProvider{name: "b", rate: 2},
+			},
+			expectedError: ErrNoConsensus,
+		},
+		{
+			name: "should fail when every provider fails",
+			providers: []RateProvider{
+				staticProvider{name: "a", err: ErrUnsupportedCode},
+			},
+			expectedError: ErrUnsupportedCode,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewConsensus(0.02, tc.providers...)
+
+			rate, err := p.GetPair(context.Background(), "USD", "EUR")
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRate, rate.Rate.String())
+			assert.Equal(t, tc.expectedSources, rate.Sources)
+			assert.InDelta(t, tc.expectedSpread, rate.Spread, 1e-9)
+		})
+	}
+}
+
+func TestConsensus_GetCodes(t *testing.T) {
+	p := NewConsensus(0.01, staticProvider{name: "a"}, staticProvider{name: "b"})
+
+	codes, err := p.GetCodes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Currency{{Code: "USD", Name: "a"}, {Code: "a", Name: "a"}, {Code: "b", Name: "b"}}, codes)
+}
+
+func TestNew_Consensus(t *testing.T) {
+	p, err := New(Config{Name: "exchangerate-api, ecb", Strategy: StrategyConsensus, Tolerance: 0.01})
+	assert.NoError(t, err)
+	assert.Equal(t, "consensus(exchangerate-api,ecb)", p.Name())
+
+	// Providers are tried in order unless a consensus is asked for
+	p, err = New(Config{Name: "exchangerate-api, ecb"})
+	assert.NoError(t, err)
+	assert.Equal(t, "failover(exchangerate-api,ecb)", p.Name())
+
+	// Each provider keeps its own endpoint
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":"success","conversion_rate":1.08}`))
+	}))
+	defer srv.Close()
+
+	p, err = New(Config{
+		Name:     "exchangerate-api,ecb",
+		BaseURL:  srv.URL + ", testdata/eurofxref-daily.xml",
+		Strategy: StrategyConsensus,
+	})
+	assert.NoError(t, err)
+
+	rate, err := p.GetPair(context.Background(), "EUR", "USD")
+	assert.NoError(t, err)
+	assert.True(t, rate.Rate.Equal(decimal.MustParse("1.08")), rate.Rate.String())
+	assert.Equal(t, []string{"ecb", "exchangerate-api"}, rate.Sources)
+	assert.Equal(t, "ecb,exchangerate-api", rate.ExchangeRate().Source)
+
+	_, err = New(Config{Name: "exchangerate-api,ecb", BaseURL: "http://localhost:8081"})
+	assert.ErrorIs(t, err, ErrBaseURLs)
+}
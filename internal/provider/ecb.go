@@ -0,0 +1,249 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+)
+
+const (
+	ECBName       = "ecb"
+	ecbDailyURL   = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ecbBaseCode   = "EUR"
+	ecbDateLayout = "2006-01-02"
+	// ECB reference rates are published around 16:00 CET on working days
+	ecbPublishHour = 15
+)
+
+// ECB reads the European Central Bank euro foreign exchange reference rates.
+// The source is either a URL or a local path to an eurofxref-daily.xml or
+// eurofxref-hist.xml file. Rates are published against EUR, every other pair
+// is derived from them.
+type ECB struct {
+	source string
+	client *http.Client
+	cache  *responseCache
+}
+
+// ECBDay holds the EUR based reference rates published for a single day
+type ECBDay struct {
+	Date  time.Time
+	Rates map[string]decimal.Decimal
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func NewECB(source string) *ECB {
+	if source == "" {
+		source = ecbDailyURL
+	}
+
+	return &ECB{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *ECB) Name() string {
+	return ECBName
+}
+
+func (p *ECB) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	days, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return days[0].pair(base, target)
+}
+
+func (p *ECB) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	days, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day := days[0]
+	if _, ok := day.Rates[base]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCode, base)
+	}
+
+	rates := make([]Rate, 0, len(day.Rates)-1)
+	for _, target := range day.codes() {
+		if target == base {
+			continue
+		}
+
+		rate, err := day.pair(base, target)
+		if err != nil {
+			return nil, err
+		}
+		rates = append(rates, *rate)
+	}
+
+	return rates, nil
+}
+
+func (p *ECB) GetCodes(ctx context.Context) ([]Currency, error) {
+	days, err := p.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The ECB feed has no currency names, the code is used instead
+	codes := days[0].codes()
+	currencies := make([]Currency, 0, len(codes))
+	for _, code := range codes {
+		currencies = append(currencies, Currency{Code: code, Name: code})
+	}
+
+	return currencies, nil
+}
+
+func (p *ECB) load(ctx context.Context) ([]ECBDay, error) {
+	var r io.Reader
+
+	if strings.HasPrefix(p.source, "http://") || strings.HasPrefix(p.source, "https://") {
+		body, status, err := p.cache.fetch(ctx, p.client, p.source, p.source, ecbExpiry)
+		if err != nil {
+			return nil, err
+		}
+
+		if status > 299 {
+			return nil, fmt.Errorf("%w: status code %d", errUpstream, status)
+		}
+		r = bytes.NewReader(body)
+	} else {
+		f, err := os.Open(p.source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	days, err := ParseECB(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(days) == 0 {
+		return nil, fmt.Errorf("%w: no reference rates in %s", errUpstream, p.source)
+	}
+
+	return days, nil
+}
+
+// ecbExpiry keeps the reference rates until the next publication
+func ecbExpiry(body []byte) time.Time {
+	days, err := ParseECB(bytes.NewReader(body))
+	if err != nil || len(days) == 0 {
+		return time.Time{}
+	}
+
+	return nextWorkingDay(days[0].Date.Add(ecbPublishHour * time.Hour))
+}
+
+// ParseECB parses the eurofxref-daily.xml and eurofxref-hist.xml formats.
+// Days are returned newest first and always contain EUR itself.
+func ParseECB(r io.Reader) ([]ECBDay, error) {
+	var envelope ecbEnvelope
+
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, err
+	}
+
+	days := make([]ECBDay, 0, len(envelope.Days))
+	for _, d := range envelope.Days {
+		date, err := time.Parse(ecbDateLayout, d.Time)
+		if err != nil {
+			return nil, err
+		}
+
+		day := ECBDay{
+			Date:  date,
+			Rates: map[string]decimal.Decimal{ecbBaseCode: decimal.One},
+		}
+		for _, rate := range d.Rates {
+			value, err := decimal.Parse(rate.Rate)
+			if err != nil {
+				return nil, err
+			}
+			day.Rates[rate.Currency] = value
+		}
+
+		days = append(days, day)
+	}
+
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Date.After(days[j].Date)
+	})
+
+	return days, nil
+}
+
+// pair derives the base/target rate through EUR, pairs quoted against EUR
+// keep the published figure
+func (d ECBDay) pair(base, target string) (*Rate, error) {
+	baseRate, ok := d.Rates[base]
+	if !ok || baseRate.IsZero() {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCode, base)
+	}
+
+	targetRate, ok := d.Rates[target]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCode, target)
+	}
+
+	rate := targetRate
+	if base != ecbBaseCode {
+		rate = targetRate.Div(baseRate, decimal.DivisionScale)
+	}
+
+	lastUpdate := d.Date.Add(ecbPublishHour * time.Hour)
+
+	return &Rate{
+		BaseCode:   base,
+		TargetCode: target,
+		Rate:       rate,
+		LastUpdate: lastUpdate,
+		NextUpdate: nextWorkingDay(lastUpdate),
+		Sources:    []string{ECBName},
+	}, nil
+}
+
+func (d ECBDay) codes() []string {
+	codes := make([]string, 0, len(d.Rates))
+	for code := range d.Rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+func nextWorkingDay(t time.Time) time.Time {
+	next := t.AddDate(0, 0, 1)
+	for next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
@@ -0,0 +1,75 @@
+package provider
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseECB(t *testing.T) {
+	f, err := os.Open("testdata/eurofxref-hist.xml")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	days, err := ParseECB(f)
+	assert.NoError(t, err)
+	assert.Len(t, days, 3)
+
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), days[0].Date)
+	rates := make(map[string]string)
+	for code, rate := range days[0].Rates {
+		rates[code] = rate.String()
+	}
+	assert.Equal(t, map[string]string{"EUR": "1", "USD": "1.0800", "JPY": "162.00"}, rates)
+	assert.Equal(t, time.Date(2024, 2, 28, 0, 0, 0, 0, time.UTC), days[2].Date)
+}
+
+func TestECB_GetPair(t *testing.T) {
+	p := NewECB("testdata/eurofxref-daily.xml")
+
+	tests := []struct {
+		name          string
+		base          string
+		target        string
+		expectedRate  string
+		expectedError error
+	}{
+		{name: "direct EUR pair", base: "EUR", target: "USD", expectedRate: "1.0800"},
+		{name: "inverse pair", base: "USD", target: "EUR", expectedRate: "0.9259259259259259"},
+		{name: "cross pair", base: "USD", target: "JPY", expectedRate: "150.0000000000000000"},
+		{name: "small cross pair", base: "JPY", target: "GBP", expectedRate: "0.0052777777777778"},
+		{name: "unsupported code", base: "USD", target: "VND", expectedError: ErrUnsupportedCode},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := p.GetPair(context.Background(), tc.base, tc.target)
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRate, rate.Rate.String())
+			assert.Equal(t, tc.base, rate.BaseCode)
+			assert.Equal(t, tc.target, rate.TargetCode)
+			// 2024-03-01 is a Friday, the next publication is on Monday
+			assert.Equal(t, time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC), rate.NextUpdate)
+		})
+	}
+}
+
+func TestECB_GetLatest(t *testing.T) {
+	p := NewECB("testdata/eurofxref-daily.xml")
+
+	rates, err := p.GetLatest(context.Background(), "EUR")
+	assert.NoError(t, err)
+	assert.Len(t, rates, 3)
+	for _, rate := range rates {
+		assert.Equal(t, "EUR", rate.BaseCode)
+		assert.NotEqual(t, "EUR", rate.TargetCode)
+	}
+}
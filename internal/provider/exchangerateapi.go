@@ -0,0 +1,185 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+)
+
+const (
+	ExchangeRateAPIName    = "exchangerate-api"
+	exchangeRateAPIBaseURL = "https://v6.exchangerate-api.com/v6"
+)
+
+var errUpstream = errors.New("upstream provider error")
+
+// ExchangeRateAPI is a client for https://www.exchangerate-api.com
+type ExchangeRateAPI struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+	cache   *responseCache
+}
+
+type exchangeRateAPIResponse struct {
+	Result             string                 `json:"result"`
+	ErrorType          string                 `json:"error-type"`
+	TimeLastUpdateUnix int64                  `json:"time_last_update_unix"`
+	TimeNextUpdateUnix int64                  `json:"time_next_update_unix"`
+	BaseCode           string                 `json:"base_code"`
+	TargetCode         string                 `json:"target_code"`
+	ConversionRate     json.Number            `json:"conversion_rate"`
+	ConversionRates    map[string]json.Number `json:"conversion_rates"`
+	SupportedCodes     [][]string             `json:"supported_codes"`
+}
+
+func NewExchangeRateAPI(apiKey, baseURL string) *ExchangeRateAPI {
+	if baseURL == "" {
+		baseURL = exchangeRateAPIBaseURL
+	}
+
+	return &ExchangeRateAPI{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *ExchangeRateAPI) Name() string {
+	return ExchangeRateAPIName
+}
+
+func (p *ExchangeRateAPI) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	var data exchangeRateAPIResponse
+
+	if err := p.get(ctx, fmt.Sprintf("pair/%s/%s", base, target), &data); err != nil {
+		return nil, err
+	}
+
+	rate, err := parseQuote(data.ConversionRate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rate{
+		BaseCode:   base,
+		TargetCode: target,
+		Rate:       rate,
+		LastUpdate: time.Unix(data.TimeLastUpdateUnix, 0).UTC(),
+		NextUpdate: time.Unix(data.TimeNextUpdateUnix, 0).UTC(),
+		Sources:    []string{ExchangeRateAPIName},
+	}, nil
+}
+
+func (p *ExchangeRateAPI) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	var data exchangeRateAPIResponse
+
+	if err := p.get(ctx, "latest/"+base, &data); err != nil {
+		return nil, err
+	}
+
+	lastUpdate := time.Unix(data.TimeLastUpdateUnix, 0).UTC()
+	nextUpdate := time.Unix(data.TimeNextUpdateUnix, 0).UTC()
+
+	rates := make([]Rate, 0, len(data.ConversionRates))
+	for target, quote := range data.ConversionRates {
+		if target == base {
+			continue
+		}
+
+		rate, err := parseQuote(quote)
+		if err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, Rate{
+			BaseCode:   base,
+			TargetCode: target,
+			Rate:       rate,
+			LastUpdate: lastUpdate,
+			NextUpdate: nextUpdate,
+			Sources:    []string{ExchangeRateAPIName},
+		})
+	}
+
+	return rates, nil
+}
+
+func (p *ExchangeRateAPI) GetCodes(ctx context.Context) ([]Currency, error) {
+	var data exchangeRateAPIResponse
+
+	if err := p.get(ctx, "codes", &data); err != nil {
+		return nil, err
+	}
+
+	currencies := make([]Currency, 0, len(data.SupportedCodes))
+	for _, code := range data.SupportedCodes {
+		if len(code) < 2 {
+			continue
+		}
+		currencies = append(currencies, Currency{Code: code[0], Name: code[1]})
+	}
+
+	return currencies, nil
+}
+
+// get requests endpoint, e.g. "pair/USD/EUR", and decodes the response into data
+func (p *ExchangeRateAPI) get(ctx context.Context, endpoint string, data *exchangeRateAPIResponse) error {
+	url := fmt.Sprintf("%s/%s/%s", p.baseURL, p.apiKey, endpoint)
+
+	body, status, err := p.cache.fetch(ctx, p.client, endpoint, url, exchangeRateAPIExpiry)
+	if err != nil {
+		return err
+	}
+
+	if status == http.StatusTooManyRequests {
+		return ErrRateLimited
+	}
+
+	// exchangerate-api reports failures in the body, usually along with a 4xx status code
+	if err = json.Unmarshal(body, data); err != nil {
+		if status > 299 {
+			return fmt.Errorf("%w: status code %d", errUpstream, status)
+		}
+		return err
+	}
+
+	if data.Result != "success" {
+		switch data.ErrorType {
+		case "unsupported-code", "malformed-request":
+			return fmt.Errorf("%w: %s", ErrUnsupportedCode, data.ErrorType)
+		case "quota-reached":
+			return fmt.Errorf("%w: %s", ErrRateLimited, data.ErrorType)
+		default:
+			return fmt.Errorf("%w: %s (status code %d)", errUpstream, data.ErrorType, status)
+		}
+	}
+
+	return nil
+}
+
+// parseQuote reads a rate from its JSON literal, never going through a float
+func parseQuote(n json.Number) (decimal.Decimal, error) {
+	return decimal.ParseRound(n.String(), decimal.DivisionScale)
+}
+
+// exchangeRateAPIExpiry keeps successful responses until the provider's next update
+func exchangeRateAPIExpiry(body []byte) time.Time {
+	var data exchangeRateAPIResponse
+
+	if err := json.Unmarshal(body, &data); err != nil || data.Result != "success" {
+		return time.Time{}
+	}
+
+	// The supported codes carry no update window
+	if data.TimeNextUpdateUnix == 0 {
+		return time.Now().Add(24 * time.Hour)
+	}
+
+	return time.Unix(data.TimeNextUpdateUnix, 0)
+}
@@ -0,0 +1,124 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExchangeRateAPI_GetPair(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		body          string
+		expectedRate  *Rate
+		expectedError error
+	}{
+		{
+			name:   "should return the pair rate",
+			status: http.StatusOK,
+			body: `{"result":"success","time_last_update_unix":1700000000,"time_next_update_unix":1700086400,
+				"base_code":"USD","target_code":"EUR","conversion_rate":0.9}`,
+			expectedRate: &Rate{
+				BaseCode:   "USD",
+				TargetCode: "EUR",
+				Rate:       decimal.MustParse("0.9"),
+				LastUpdate: time.Unix(1700000000, 0).UTC(),
+				NextUpdate: time.Unix(1700086400, 0).UTC(),
+				Sources:    []string{ExchangeRateAPIName},
+			},
+		},
+		{
+			name:          "should return unsupported code error",
+			status:        http.StatusNotFound,
+			body:          `{"result":"error","error-type":"unsupported-code"}`,
+			expectedError: ErrUnsupportedCode,
+		},
+		{
+			name:          "should return rate limited error",
+			status:        http.StatusForbidden,
+			body:          `{"result":"error","error-type":"quota-reached"}`,
+			expectedError: ErrRateLimited,
+		},
+		{
+			name:          "should return upstream error",
+			status:        http.StatusForbidden,
+			body:          `{"result":"error","error-type":"invalid-key"}`,
+			expectedError: errUpstream,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/key/pair/USD/EUR", r.URL.Path)
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			p := NewExchangeRateAPI("key", srv.URL)
+
+			rate, err := p.GetPair(context.Background(), "USD", "EUR")
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedRate, rate)
+		})
+	}
+}
+
+func TestExchangeRateAPI_GetLatest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/key/latest/USD", r.URL.Path)
+		_, _ = w.Write([]byte(`{"result":"success","time_last_update_unix":1700000000,"time_next_update_unix":1700086400,
+			"base_code":"USD","conversion_rates":{"USD":1,"EUR":0.9,"JPY":150.5,"GBP":0.006191950464396285}}`))
+	}))
+	defer srv.Close()
+
+	p := NewExchangeRateAPI("key", srv.URL)
+
+	rates, err := p.GetLatest(context.Background(), "USD")
+	assert.NoError(t, err)
+	assert.Len(t, rates, 3)
+
+	got := make(map[string]string)
+	for _, rate := range rates {
+		assert.Equal(t, "USD", rate.BaseCode)
+		got[rate.TargetCode] = rate.Rate.String()
+	}
+	assert.Equal(t, map[string]string{"EUR": "0.9", "JPY": "150.5", "GBP": "0.0061919504643963"}, got)
+}
+
+func TestExchangeRateAPI_GetCodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/key/codes", r.URL.Path)
+		_, _ = w.Write([]byte(`{"result":"success","supported_codes":[["USD","United States Dollar"],["EUR","Euro"]]}`))
+	}))
+	defer srv.Close()
+
+	p := NewExchangeRateAPI("key", srv.URL)
+
+	codes, err := p.GetCodes(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []Currency{{Code: "USD", Name: "United States Dollar"}, {Code: "EUR", Name: "Euro"}}, codes)
+}
+
+func TestNew(t *testing.T) {
+	p, err := New(Config{Name: ExchangeRateAPIName, APIKey: "key"})
+	assert.NoError(t, err)
+	assert.Equal(t, ExchangeRateAPIName, p.Name())
+	// A single provider is guarded too
+	assert.Len(t, p.(BreakerReporter).Breakers(), 1)
+
+	_, err = New(Config{Name: "unknown"})
+	assert.ErrorIs(t, err, ErrUnknownProvider)
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const FailoverName = "failover"
+
+// Failover calls providers in order and returns the first successful answer
+type Failover struct {
+	providers []RateProvider
+}
+
+func NewFailover(providers ...RateProvider) *Failover {
+	return &Failover{providers: providers}
+}
+
+func (p *Failover) Name() string {
+	names := make([]string, 0, len(p.providers))
+	for _, provider := range p.providers {
+		names = append(names, provider.Name())
+	}
+
+	return fmt.Sprintf("%s(%s)", FailoverName, strings.Join(names, ","))
+}
+
+func (p *Failover) GetPair(ctx context.Context, base, target string) (*Rate, error) {
+	return firstOf(p.providers, func(provider RateProvider) (*Rate, error) {
+		rate, err := provider.GetPair(ctx, base, target)
+		if err != nil {
+			return nil, err
+		}
+		rate.Sources = []string{provider.Name()}
+		return rate, nil
+	})
+}
+
+func (p *Failover) GetLatest(ctx context.Context, base string) ([]Rate, error) {
+	return firstOf(p.providers, func(provider RateProvider) ([]Rate, error) {
+		rates, err := provider.GetLatest(ctx, base)
+		if err != nil {
+			return nil, err
+		}
+		for i := range rates {
+			rates[i].Sources = []string{provider.Name()}
+		}
+		return rates, nil
+	})
+}
+
+func (p *Failover) GetCodes(ctx context.Context) ([]Currency, error) {
+	return firstOf(p.providers, func(provider RateProvider) ([]Currency, error) {
+		return provider.GetCodes(ctx)
+	})
+}
+
+func (p *Failover) Breakers() []BreakerStatus {
+	return collectBreakers(p.providers)
+}
+
+func firstOf[T any](providers []RateProvider, call func(RateProvider) (T, error)) (T, error) {
+	var errs []error
+
+	for _, provider := range providers {
+		result, err := call(provider)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	var zero T
+	return zero, errors.Join(errs...)
+}
+
+func collectBreakers(providers []RateProvider) []BreakerStatus {
+	var statuses []BreakerStatus
+
+	for _, provider := range providers {
+		if reporter, ok := provider.(BreakerReporter); ok {
+			statuses = append(statuses, reporter.Breakers()...)
+		}
+	}
+
+	return statuses
+}
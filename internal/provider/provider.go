@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUnsupportedCode = errors.New("unsupported currency code")
+	ErrUnknownProvider = errors.New("unknown rate provider")
+	ErrRateLimited     = errors.New("rate provider quota exhausted")
+	ErrBaseURLs        = errors.New("base URLs must list one endpoint per provider")
+)
+
+const (
+	StrategyConsensus = "consensus"
+	StrategyFailover  = "failover"
+)
+
+const defaultBreakerCooldown = 30 * time.Second
+
+// RateProvider is an upstream source of exchange rates.
+type RateProvider interface {
+	// Name returns the identifier of the provider, e.g. "exchangerate-api"
+	Name() string
+	// GetPair returns the conversion rate from base to target
+	GetPair(ctx context.Context, base, target string) (*Rate, error)
+	// GetLatest returns every conversion rate available for base
+	GetLatest(ctx context.Context, base string) ([]Rate, error)
+	// GetCodes returns the currencies supported by the provider
+	GetCodes(ctx context.Context) ([]Currency, error)
+}
+
+type Rate struct {
+	BaseCode   string
+	TargetCode string
+	Rate       decimal.Decimal
+	LastUpdate time.Time
+	NextUpdate time.Time
+	// Sources lists the providers that answered with the rate, the one that
+	// succeeded for a failover and the agreeing ones for a consensus
+	Sources []string
+	// Spread is the relative distance between the lowest and highest contributing quote
+	Spread float64
+}
+
+// Source names the providers that answered, as recorded with stored rates
+func (r *Rate) Source() string {
+	return strings.Join(r.Sources, ",")
+}
+
+// ExchangeRate returns r in its stored form, credited to the providers that answered
+func (r *Rate) ExchangeRate() *store.ExchangeRate {
+	return &store.ExchangeRate{
+		NextUpdate: r.NextUpdate,
+		BaseCode:   r.BaseCode,
+		TargetCode: r.TargetCode,
+		LastUpdate: r.LastUpdate,
+		Rate:       r.Rate,
+		Sources:    r.Sources,
+		Spread:     r.Spread,
+		Source:     r.Source(),
+	}
+}
+
+type Currency struct {
+	Code string
+	Name string
+}
+
+type Config struct {
+	// Name is a single provider name, or a comma separated list to build a consensus
+	Name   string
+	APIKey string
+	// BaseURL overrides the default endpoint, a comma separated list gives one
+	// per provider in the order of Name. The ECB provider also accepts a local file path.
+	BaseURL string
+	// Strategy combines several providers, either StrategyFailover (default), which
+	// tries them in the order of Name, or StrategyConsensus
+	Strategy string
+	// Tolerance is the relative deviation from the median allowed by the consensus
+	Tolerance float64
+	// BreakerThreshold is the number of consecutive failures that opens a provider circuit
+	BreakerThreshold int
+	// BreakerCooldown is how long an open circuit waits before a trial call, e.g. "30s"
+	BreakerCooldown string
+	// Cache keeps upstream responses until the provider's next update
+	Cache bool
+	// Logger receives cache decisions, slog.Default is used when nil
+	Logger *slog.Logger
+	// Meter counts upstream calls and enforces budgets, nil disables metering
+	Meter *Meter
+}
+
+// New returns the provider registered under cfg.Name guarded by a
+// CircuitBreaker. When several names are given the providers are combined
+// according to cfg.Strategy.
+func New(cfg Config) (RateProvider, error) {
+	cooldown := defaultBreakerCooldown
+	if cfg.BreakerCooldown != "" {
+		var err error
+		if cooldown, err = time.ParseDuration(cfg.BreakerCooldown); err != nil {
+			return nil, err
+		}
+	}
+
+	names := strings.Split(cfg.Name, ",")
+	if len(names) == 1 {
+		provider, err := newProvider(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return NewCircuitBreaker(provider, cfg.BreakerThreshold, cooldown), nil
+	}
+
+	// An empty entry keeps the default endpoint of its provider
+	urls := make([]string, len(names))
+	if cfg.BaseURL != "" {
+		urls = strings.Split(cfg.BaseURL, ",")
+		if len(urls) != len(names) {
+			return nil, ErrBaseURLs
+		}
+	}
+
+	providers := make([]RateProvider, 0, len(names))
+	for i, name := range names {
+		provider, err := newProvider(Config{
+			Name:    strings.TrimSpace(name),
+			APIKey:  cfg.APIKey,
+			BaseURL: strings.TrimSpace(urls[i]),
+			Cache:   cfg.Cache,
+			Logger:  cfg.Logger,
+			Meter:   cfg.Meter,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, NewCircuitBreaker(provider, cfg.BreakerThreshold, cooldown))
+	}
+
+	switch cfg.Strategy {
+	case StrategyFailover, "":
+		return NewFailover(providers...), nil
+	case StrategyConsensus:
+		return NewConsensus(cfg.Tolerance, providers...), nil
+	default:
+		return nil, fmt.Errorf("unknown provider strategy: %s", cfg.Strategy)
+	}
+}
+
+func newProvider(cfg Config) (RateProvider, error) {
+	switch cfg.Name {
+	case ExchangeRateAPIName:
+		p := NewExchangeRateAPI(cfg.APIKey, cfg.BaseURL)
+		if cfg.Cache {
+			p.cache = newResponseCache(cfg.Name, cfg.Logger)
+		}
+		if cfg.Meter != nil {
+			p.client.Transport = cfg.Meter.Transport(cfg.Name, p.client.Transport)
+		}
+		return p, nil
+	case ECBName:
+		p := NewECB(cfg.BaseURL)
+		if cfg.Cache {
+			p.cache = newResponseCache(cfg.Name, cfg.Logger)
+		}
+		if cfg.Meter != nil {
+			p.client.Transport = cfg.Meter.Transport(cfg.Name, p.client.Transport)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownProvider, cfg.Name)
+	}
+}
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrBudgetExhausted = errors.New("rate provider call budget exhausted")
+
+// UsageStore persists upstream call counts, implemented by store.ProviderUsageStorage
+type UsageStore interface {
+	// Reserve atomically counts one call unless it would exceed the daily or
+	// monthly limit, zero limits are unlimited
+	Reserve(ctx context.Context, provider string, at time.Time, daily, monthly int) (bool, error)
+	Usage(ctx context.Context, provider string, at time.Time) (daily, monthly int, err error)
+}
+
+// Budget limits the calls made to a provider, zero means unlimited
+type Budget struct {
+	Daily   int `json:"daily"`
+	Monthly int `json:"monthly"`
+}
+
+type UsageReport struct {
+	Provider string `json:"provider"`
+	Daily    int    `json:"daily"`
+	Monthly  int    `json:"monthly"`
+	Budget   Budget `json:"budget"`
+	// Exhausted is true when either limit has been reached
+	Exhausted bool `json:"exhausted"`
+}
+
+// Meter counts every request sent upstream and refuses new ones once the
+// provider's budget is spent. Responses served from the cache are free.
+type Meter struct {
+	store   UsageStore
+	budgets map[string]Budget
+	now     func() time.Time
+}
+
+func NewMeter(store UsageStore, budgets map[string]Budget) *Meter {
+	return &Meter{
+		store:   store,
+		budgets: budgets,
+		now:     time.Now,
+	}
+}
+
+// ParseBudgets reads "name:daily:monthly" entries separated by commas,
+// e.g. "exchangerate-api:0:1500"
+func ParseBudgets(s string) (map[string]Budget, error) {
+	budgets := make(map[string]Budget)
+
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid provider budget %q, expected name:daily:monthly", entry)
+		}
+
+		daily, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid daily budget %q: %w", entry, err)
+		}
+
+		monthly, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid monthly budget %q: %w", entry, err)
+		}
+
+		budgets[parts[0]] = Budget{Daily: daily, Monthly: monthly}
+	}
+
+	return budgets, nil
+}
+
+// Reserve counts a call to the provider, or returns ErrBudgetExhausted when
+// the call would exceed its budget
+func (m *Meter) Reserve(ctx context.Context, provider string) error {
+	budget := m.budgets[provider]
+
+	ok, err := m.store.Reserve(ctx, provider, m.now(), budget.Daily, budget.Monthly)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s allows %d calls a day and %d a month",
+			ErrBudgetExhausted, provider, budget.Daily, budget.Monthly)
+	}
+
+	return nil
+}
+
+// Report returns the usage of every budgeted provider and of the given ones
+func (m *Meter) Report(ctx context.Context, providers ...string) ([]UsageReport, error) {
+	names := make(map[string]bool)
+	for name := range m.budgets {
+		names[name] = true
+	}
+	for _, name := range providers {
+		names[name] = true
+	}
+
+	reports := make([]UsageReport, 0, len(names))
+	for name := range names {
+		daily, monthly, err := m.store.Usage(ctx, name, m.now())
+		if err != nil {
+			return nil, err
+		}
+
+		budget := m.budgets[name]
+		reports = append(reports, UsageReport{
+			Provider:  name,
+			Daily:     daily,
+			Monthly:   monthly,
+			Budget:    budget,
+			Exhausted: budget.exhausted(daily, monthly),
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Provider < reports[j].Provider
+	})
+
+	return reports, nil
+}
+
+// Transport meters the requests sent by a provider's http client
+func (m *Meter) Transport(provider string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	return &meteredTransport{meter: m, provider: provider, next: next}
+}
+
+func (b Budget) exhausted(daily, monthly int) bool {
+	return (b.Daily > 0 && daily >= b.Daily) || (b.Monthly > 0 && monthly >= b.Monthly)
+}
+
+type meteredTransport struct {
+	meter    *Meter
+	provider string
+	next     http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The call is counted before it is sent so that concurrent requests cannot
+	// overshoot the budget, failed requests count against the quota as well
+	if err := t.meter.Reserve(req.Context(), t.provider); err != nil {
+		return nil, err
+	}
+
+	return t.next.RoundTrip(req)
+}
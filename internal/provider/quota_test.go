@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/fakeprovider"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryUsage struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (m *memoryUsage) Reserve(_ context.Context, provider string, _ time.Time, daily, monthly int) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := m.calls[provider]
+	if (daily > 0 && calls >= daily) || (monthly > 0 && calls >= monthly) {
+		return false, nil
+	}
+
+	m.calls[provider]++
+	return true, nil
+}
+
+func (m *memoryUsage) Usage(_ context.Context, provider string, _ time.Time) (int, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls[provider], m.calls[provider], nil
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets("exchangerate-api:100:1500, ecb:0:0")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Budget{
+		"exchangerate-api": {Daily: 100, Monthly: 1500},
+		"ecb":              {},
+	}, budgets)
+
+	_, err = ParseBudgets("exchangerate-api:100")
+	assert.Error(t, err)
+}
+
+func TestMeter(t *testing.T) {
+	fake, srv := fakeprovider.NewTestServer(fakeprovider.DefaultFixtures())
+	defer srv.Close()
+
+	usage := &memoryUsage{calls: make(map[string]int)}
+	meter := NewMeter(usage, map[string]Budget{ExchangeRateAPIName: {Daily: 2}})
+
+	p, err := New(Config{Name: ExchangeRateAPIName, APIKey: "key", BaseURL: srv.URL, Cache: true, Meter: meter})
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	_, err = p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+
+	// Cached responses are free
+	_, err = p.GetPair(ctx, "USD", "EUR")
+	assert.NoError(t, err)
+
+	_, err = p.GetPair(ctx, "USD", "JPY")
+	assert.NoError(t, err)
+
+	_, err = p.GetPair(ctx, "USD", "GBP")
+	assert.ErrorIs(t, err, ErrBudgetExhausted)
+	assert.Equal(t, 2, fake.Requests())
+
+	reports, err := meter.Report(ctx, ECBName)
+	assert.NoError(t, err)
+	assert.Equal(t, []UsageReport{
+		{Provider: ECBName},
+		{Provider: ExchangeRateAPIName, Daily: 2, Monthly: 2, Budget: Budget{Daily: 2}, Exhausted: true},
+	}, reports)
+}
@@ -0,0 +1,208 @@
+// Package rategraph derives conversion rates between currencies that have
+// no stored pair, by chaining stored pairs and their inverses.
+package rategraph
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+)
+
+// MaxHops bounds the length of a derived path
+const MaxHops = 4
+
+var ErrNoPath = errors.New("no conversion path")
+
+type Provenance string
+
+const (
+	Direct  Provenance = "direct"
+	Inverse Provenance = "inverse"
+	Derived Provenance = "derived"
+)
+
+// Hop is one step of a path, taken along a stored pair or against it
+type Hop struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Rate       decimal.Decimal `json:"rate"`
+	Bid        decimal.Decimal `json:"bid"`
+	Ask        decimal.Decimal `json:"ask"`
+	Inverse    bool            `json:"inverse"`
+	LastUpdate time.Time       `json:"last_update"`
+	NextUpdate time.Time       `json:"next_update"`
+}
+
+type Path struct {
+	Hops []Hop `json:"hops"`
+	// Rate, Bid and Ask are the exact products of every hop
+	Rate decimal.Decimal `json:"rate"`
+	Bid  decimal.Decimal `json:"bid"`
+	Ask  decimal.Decimal `json:"ask"`
+	// LastUpdate is the oldest observation on the path
+	LastUpdate time.Time `json:"last_update"`
+	// NextUpdate is the earliest scheduled refresh on the path
+	NextUpdate time.Time `json:"next_update"`
+}
+
+type Graph struct {
+	edges map[string][]Hop
+}
+
+// New builds a graph where every stored pair can be walked in both directions
+func New(rates []store.ExchangeRate) *Graph {
+	g := &Graph{edges: make(map[string][]Hop)}
+
+	for _, rate := range rates {
+		if rate.Rate.IsZero() || rate.Bid.IsZero() || rate.Ask.IsZero() {
+			continue
+		}
+
+		g.edges[rate.BaseCode] = append(g.edges[rate.BaseCode], Hop{
+			From:       rate.BaseCode,
+			To:         rate.TargetCode,
+			Rate:       rate.Rate,
+			Bid:        rate.Bid,
+			Ask:        rate.Ask,
+			LastUpdate: rate.LastUpdate,
+			NextUpdate: rate.NextUpdate,
+		})
+		// Selling the target buys the base, so the inverse bid comes from the ask
+		g.edges[rate.TargetCode] = append(g.edges[rate.TargetCode], Hop{
+			From:       rate.TargetCode,
+			To:         rate.BaseCode,
+			Rate:       decimal.One.Div(rate.Rate, decimal.DivisionScale),
+			Bid:        decimal.One.Div(rate.Ask, decimal.DivisionScale),
+			Ask:        decimal.One.Div(rate.Bid, decimal.DivisionScale),
+			Inverse:    true,
+			LastUpdate: rate.LastUpdate,
+			NextUpdate: rate.NextUpdate,
+		})
+	}
+
+	// Walking edges in a fixed order makes ties between equally fresh paths
+	// resolve the same way on every call
+	for _, hops := range g.edges {
+		sort.Slice(hops, func(i, j int) bool {
+			if hops[i].To != hops[j].To {
+				return hops[i].To < hops[j].To
+			}
+			return !hops[i].Inverse && hops[j].Inverse
+		})
+	}
+
+	return g
+}
+
+// PathOf returns the path made of a single hop
+func PathOf(hop Hop) *Path {
+	return identity().extend(hop)
+}
+
+// Find returns the path with the fewest hops from base to target. Among
+// equally short paths the freshest one, whose oldest hop is the most recent,
+// wins. Remaining ties go to the path through the alphabetically first codes.
+func (g *Graph) Find(base, target string) (*Path, error) {
+	if base == target {
+		return identity(), nil
+	}
+
+	// A stored pair beats its inverse and any chain
+	for _, hop := range g.edges[base] {
+		if hop.To == target && !hop.Inverse {
+			return identity().extend(hop), nil
+		}
+	}
+
+	best := map[string]*Path{base: identity()}
+	frontier := []string{base}
+
+	for depth := 0; depth < MaxHops && len(frontier) > 0; depth++ {
+		next := make(map[string]*Path)
+
+		for _, code := range frontier {
+			from := best[code]
+
+			for _, hop := range g.edges[code] {
+				// Reached with fewer hops already
+				if _, ok := best[hop.To]; ok {
+					continue
+				}
+
+				candidate := from.extend(hop)
+				if current, ok := next[hop.To]; !ok || candidate.LastUpdate.After(current.LastUpdate) {
+					next[hop.To] = candidate
+				}
+			}
+		}
+
+		if path, ok := next[target]; ok {
+			return path, nil
+		}
+
+		frontier = frontier[:0]
+		for code, path := range next {
+			best[code] = path
+			frontier = append(frontier, code)
+		}
+		sort.Strings(frontier)
+	}
+
+	return nil, fmt.Errorf("%w from %s to %s", ErrNoPath, base, target)
+}
+
+// Codes lists the currencies visited by the path, base first
+func (p *Path) Codes() []string {
+	if len(p.Hops) == 0 {
+		return nil
+	}
+
+	codes := []string{p.Hops[0].From}
+	for _, hop := range p.Hops {
+		codes = append(codes, hop.To)
+	}
+
+	return codes
+}
+
+func (p *Path) Provenance() Provenance {
+	switch {
+	case len(p.Hops) == 1 && p.Hops[0].Inverse:
+		return Inverse
+	case len(p.Hops) <= 1:
+		return Direct
+	default:
+		return Derived
+	}
+}
+
+// identity is the empty path of a currency to itself
+func identity() *Path {
+	return &Path{Rate: decimal.One, Bid: decimal.One, Ask: decimal.One}
+}
+
+func (p *Path) extend(hop Hop) *Path {
+	path := &Path{
+		Hops:       append(append([]Hop(nil), p.Hops...), hop),
+		Rate:       p.Rate.Mul(hop.Rate),
+		Bid:        p.Bid.Mul(hop.Bid),
+		Ask:        p.Ask.Mul(hop.Ask),
+		LastUpdate: hop.LastUpdate,
+		NextUpdate: hop.NextUpdate,
+	}
+
+	if len(p.Hops) > 0 {
+		if p.LastUpdate.Before(path.LastUpdate) {
+			path.LastUpdate = p.LastUpdate
+		}
+		if p.NextUpdate.Before(path.NextUpdate) {
+			path.NextUpdate = p.NextUpdate
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,101 @@
+package rategraph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/minhnghia2k3/exchanger/internal/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func pair(base, target, rate string, updated time.Time) store.ExchangeRate {
+	mid := decimal.MustParse(rate)
+
+	return store.ExchangeRate{
+		BaseCode:   base,
+		TargetCode: target,
+		Rate:       mid,
+		Bid:        mid.Mul(decimal.MustParse("0.99")),
+		Ask:        mid.Mul(decimal.MustParse("1.01")),
+		LastUpdate: updated,
+		NextUpdate: updated.Add(time.Hour),
+	}
+}
+
+func TestGraph_Find(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	graph := New([]store.ExchangeRate{
+		pair("USD", "EUR", "0.5", now),
+		pair("USD", "JPY", "150", now.Add(-time.Hour)),
+		pair("GBP", "EUR", "2", now.Add(-2*time.Hour)),
+		pair("GBP", "JPY", "300", now.Add(-3*time.Hour)),
+		pair("CHF", "SEK", "10", now),
+	})
+
+	tests := []struct {
+		name       string
+		base       string
+		target     string
+		rate       string
+		codes      []string
+		provenance Provenance
+		lastUpdate time.Time
+	}{
+		{"direct", "USD", "EUR", "0.5", []string{"USD", "EUR"}, Direct, now},
+		{"inverse", "EUR", "USD", "2", []string{"EUR", "USD"}, Inverse, now},
+		{"derived", "EUR", "JPY", "300", []string{"EUR", "USD", "JPY"}, Derived, now.Add(-time.Hour)},
+		{"freshest of equal length", "USD", "GBP", "0.25", []string{"USD", "EUR", "GBP"}, Derived, now.Add(-2 * time.Hour)},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := graph.Find(tc.base, tc.target)
+			assert.NoError(t, err)
+			assert.True(t, decimal.MustParse(tc.rate).Equal(path.Rate), path.Rate.String())
+			assert.Equal(t, tc.codes, path.Codes())
+			assert.Equal(t, tc.provenance, path.Provenance())
+			assert.Equal(t, tc.lastUpdate, path.LastUpdate)
+		})
+	}
+
+	t.Run("bid and ask", func(t *testing.T) {
+		path, err := graph.Find("EUR", "JPY")
+		assert.NoError(t, err)
+		// EUR/USD bid is the inverse of the USD/EUR ask
+		assert.InDelta(t, 1/(0.5*1.01)*150*0.99, path.Bid.Float64(), 1e-9)
+		assert.InDelta(t, 1/(0.5*0.99)*150*1.01, path.Ask.Float64(), 1e-9)
+	})
+
+	t.Run("no path", func(t *testing.T) {
+		_, err := graph.Find("USD", "SEK")
+		assert.ErrorIs(t, err, ErrNoPath)
+	})
+
+	t.Run("stored pair beats a fresher inverse", func(t *testing.T) {
+		graph := New([]store.ExchangeRate{
+			pair("USD", "EUR", "0.5", now.Add(-time.Hour)),
+			pair("EUR", "USD", "2.1", now),
+		})
+
+		path, err := graph.Find("USD", "EUR")
+		assert.NoError(t, err)
+		assert.Equal(t, "0.5", path.Rate.String())
+		assert.Equal(t, Direct, path.Provenance())
+	})
+	t.Run("ties are broken by code", func(t *testing.T) {
+		graph := New([]store.ExchangeRate{
+			pair("USD", "GBP", "0.8", now),
+			pair("GBP", "JPY", "190", now),
+			pair("USD", "CHF", "0.9", now),
+			pair("CHF", "JPY", "168", now),
+		})
+
+		for range 20 {
+			path, err := graph.Find("USD", "JPY")
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"USD", "CHF", "JPY"}, path.Codes())
+		}
+	})
+}
@@ -0,0 +1,229 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"time"
+)
+
+// Alert kinds
+const (
+	// AlertCross fires when the rate crosses Threshold in either direction
+	AlertCross = "cross"
+	// AlertChange fires when the rate moved by more than Threshold percent over AlertChangeWindow
+	AlertChange = "change"
+)
+
+const AlertChangeWindow = 24 * time.Hour
+
+type IAlerts interface {
+	List(ctx context.Context, userID int64) ([]Alert, error)
+	Get(ctx context.Context, userID, id int64) (*Alert, error)
+	Create(ctx context.Context, alert *Alert) error
+	Update(ctx context.Context, alert *Alert) error
+	Delete(ctx context.Context, userID, id int64) error
+	ListActive(ctx context.Context, base, target string) ([]Alert, error)
+	Observe(ctx context.Context, id int64, rate decimal.Decimal) error
+	Trigger(ctx context.Context, id int64, rate decimal.Decimal, now time.Time, cooldown time.Duration) (bool, error)
+}
+
+type Alert struct {
+	ID         int64           `json:"id"`
+	UserID     int64           `json:"user_id"`
+	BaseCode   string          `json:"base_code"`
+	TargetCode string          `json:"target_code"`
+	Kind       string          `json:"kind"`
+	Threshold  decimal.Decimal `json:"threshold"`
+	Active     bool            `json:"active"`
+	// LastRate is the rate seen when the alert was last evaluated
+	LastRate        *decimal.Decimal `json:"last_rate"`
+	LastTriggeredAt *time.Time       `json:"last_triggered_at"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
+
+// Crossed reports whether moving from LastRate to rate reached or passed
+// Threshold, an alert never evaluated has not crossed
+func (a *Alert) Crossed(rate decimal.Decimal) bool {
+	if a.LastRate == nil {
+		return false
+	}
+
+	before, after := a.LastRate.Cmp(a.Threshold), rate.Cmp(a.Threshold)
+
+	return (before < 0 && after >= 0) || (before > 0 && after <= 0)
+}
+
+type AlertStorage struct {
+	db *sql.DB
+}
+
+const alertColumns = `id, user_id, base_code, target_code, kind, threshold, active, last_rate,
+	last_triggered_at, created_at`
+
+func scanAlert(row rowScanner, alert *Alert) error {
+	return row.Scan(
+		&alert.ID,
+		&alert.UserID,
+		&alert.BaseCode,
+		&alert.TargetCode,
+		&alert.Kind,
+		&alert.Threshold,
+		&alert.Active,
+		&alert.LastRate,
+		&alert.LastTriggeredAt,
+		&alert.CreatedAt,
+	)
+}
+
+func (s *AlertStorage) List(ctx context.Context, userID int64) ([]Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM rate_alerts WHERE user_id = $1 ORDER BY id`
+
+	return s.list(ctx, query, userID)
+}
+
+// ListActive returns the active alerts watching the pair
+func (s *AlertStorage) ListActive(ctx context.Context, base, target string) ([]Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM rate_alerts
+	WHERE base_code = $1 AND target_code = $2 AND active
+	ORDER BY id`
+
+	return s.list(ctx, query, base, target)
+}
+
+func (s *AlertStorage) Get(ctx context.Context, userID, id int64) (*Alert, error) {
+	var alert Alert
+
+	query := `SELECT ` + alertColumns + ` FROM rate_alerts WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	err := scanAlert(s.db.QueryRowContext(ctx, query, id, userID), &alert)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, fmt.Errorf("%w: alert %d", ErrNotFound, id)
+		default:
+			return nil, err
+		}
+	}
+
+	return &alert, nil
+}
+
+func (s *AlertStorage) Create(ctx context.Context, alert *Alert) error {
+	query := `
+	INSERT INTO rate_alerts(user_id, base_code, target_code, kind, threshold, active, last_rate)
+	VALUES($1, $2, $3, $4, $5, $6, $7)
+	RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{alert.UserID, alert.BaseCode, alert.TargetCode, alert.Kind, alert.Threshold, alert.Active,
+		alert.LastRate}
+
+	return s.db.QueryRowContext(ctx, query, args...).Scan(&alert.ID, &alert.CreatedAt)
+}
+
+func (s *AlertStorage) Update(ctx context.Context, alert *Alert) error {
+	query := `UPDATE rate_alerts SET kind = $1, threshold = $2, active = $3 WHERE id = $4 AND user_id = $5`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, alert.Kind, alert.Threshold, alert.Active, alert.ID, alert.UserID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, "alert", alert.ID)
+}
+
+func (s *AlertStorage) Delete(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM rate_alerts WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, "alert", id)
+}
+
+// Observe remembers the rate the alert was evaluated against
+func (s *AlertStorage) Observe(ctx context.Context, id int64, rate decimal.Decimal) error {
+	query := `UPDATE rate_alerts SET last_rate = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, rate, id)
+
+	return err
+}
+
+// Trigger remembers rate and marks the alert as fired unless it already fired
+// within cooldown, and reports whether the caller should notify. The rate is
+// remembered during the cooldown too, so that the same crossing does not fire
+// once it ends. The check and the update are a single statement so concurrent
+// evaluations notify at most once.
+func (s *AlertStorage) Trigger(ctx context.Context, id int64, rate decimal.Decimal, now time.Time,
+	cooldown time.Duration) (bool, error) {
+	query := `
+	UPDATE rate_alerts SET last_rate = $1,
+		last_triggered_at = CASE WHEN last_triggered_at IS NULL OR last_triggered_at <= $4
+			THEN $2 ELSE last_triggered_at END
+	WHERE id = $3
+	RETURNING last_triggered_at = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	var fired bool
+
+	err := s.db.QueryRowContext(ctx, query, rate, now, id, now.Add(-cooldown)).Scan(&fired)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return fired, nil
+}
+
+func (s *AlertStorage) list(ctx context.Context, query string, args ...any) ([]Alert, error) {
+	var alerts []Alert
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var alert Alert
+
+		if err = scanAlert(rows, &alert); err != nil {
+			return nil, err
+		}
+
+		alerts = append(alerts, alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return alerts, nil
+}
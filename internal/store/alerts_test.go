@@ -0,0 +1,42 @@
+package store
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestAlertStorage_Trigger(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	model := AlertStorage{db}
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cooldown := time.Hour
+
+	lastRate := decimal.MustParse("1.05")
+	alert := Alert{ID: 1, Kind: AlertCross, Threshold: decimal.MustParse("1.10"), LastRate: &lastRate}
+
+	// The rate crosses the threshold while the alert is cooling down
+	crossing := decimal.MustParse("1.12")
+	assert.True(t, alert.Crossed(crossing))
+
+	mock.ExpectQuery(`UPDATE rate_alerts SET last_rate = \$1`).
+		WithArgs(crossing, now, alert.ID, now.Add(-cooldown)).
+		WillReturnRows(sqlmock.NewRows([]string{"fired"}).AddRow(false))
+
+	notify, err := model.Trigger(context.Background(), alert.ID, crossing, now, cooldown)
+	assert.NoError(t, err)
+	assert.False(t, notify)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	// The crossing rate was remembered, a steady rate after the cooldown does not fire again
+	alert.LastRate = &crossing
+	assert.False(t, alert.Crossed(decimal.MustParse("1.12")))
+	assert.True(t, alert.Crossed(decimal.MustParse("1.09")))
+}
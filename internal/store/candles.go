@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"time"
+)
+
+var ErrInvalidInterval = errors.New("invalid candle interval")
+
+// candleIntervals maps the public interval names to date_trunc units
+var candleIntervals = map[string]string{
+	"1h": "hour",
+	"1d": "day",
+	"1w": "week",
+}
+
+// Candle aggregates the observations of a pair within one interval
+type Candle struct {
+	Time  time.Time       `json:"time"`
+	Open  decimal.Decimal `json:"open"`
+	High  decimal.Decimal `json:"high"`
+	Low   decimal.Decimal `json:"low"`
+	Close decimal.Decimal `json:"close"`
+	Count int             `json:"count"`
+}
+
+// Candles returns up to limit of the latest candles between from and to,
+// oldest first. A zero time leaves the bound open.
+func (s *ExchangeRateStorage) Candles(ctx context.Context, base, target, interval string, from, to time.Time,
+	limit int) ([]Candle, error) {
+	unit, ok := candleIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInterval, interval)
+	}
+
+	var candles []Candle
+
+	query := `
+	SELECT bucket, open, high, low, close, count FROM (
+		SELECT date_trunc($1, observed_at) AS bucket,
+		       (array_agg(rate ORDER BY observed_at ASC, id ASC))[1]   AS open,
+		       MAX(rate)                                               AS high,
+		       MIN(rate)                                               AS low,
+		       (array_agg(rate ORDER BY observed_at DESC, id DESC))[1] AS close,
+		       COUNT(*)                                                AS count
+		FROM exchange_rate_history
+		WHERE base_code = $2 AND target_code = $3
+		  AND ($4::timestamp IS NULL OR observed_at >= $4)
+		  AND ($5::timestamp IS NULL OR observed_at <= $5)
+		GROUP BY bucket
+		ORDER BY bucket DESC
+		LIMIT $6
+	) candles
+	ORDER BY bucket ASC`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, unit, base, target, nullTime(from), nullTime(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c Candle
+
+		if err = rows.Scan(&c.Time, &c.Open, &c.High, &c.Low, &c.Close, &c.Count); err != nil {
+			return nil, err
+		}
+
+		candles = append(candles, c)
+	}
+
+	return candles, rows.Err()
+}
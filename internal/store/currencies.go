@@ -21,6 +21,25 @@ type Currency struct {
 	Code      string  `json:"code"`
 	Name      string  `json:"name"`
 	SymbolUrl *string `json:"symbol_url"`
+	// MinorUnits is the number of decimals of the currency per ISO 4217, 2 for USD and 0 for JPY
+	MinorUnits int `json:"minor_units"`
+}
+
+// iso4217MinorUnits lists the currencies whose minor units differ from two
+var iso4217MinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// DefaultMinorUnits returns the ISO 4217 minor units of code, 2 for currencies it does not list
+func DefaultMinorUnits(code string) int {
+	if units, ok := iso4217MinorUnits[code]; ok {
+		return units
+	}
+
+	return 2
 }
 
 type CurrencyStorage struct {
@@ -28,7 +47,7 @@ type CurrencyStorage struct {
 }
 
 func (m *CurrencyStorage) GetByCode(ctx context.Context, code string) (*Currency, error) {
-	query := `SELECT id, code, name, symbol_url FROM currencies WHERE code = $1`
+	query := `SELECT id, code, name, symbol_url, minor_units FROM currencies WHERE code = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 	defer cancel()
@@ -40,6 +59,7 @@ func (m *CurrencyStorage) GetByCode(ctx context.Context, code string) (*Currency
 		&currency.Code,
 		&currency.Name,
 		&currency.SymbolUrl,
+		&currency.MinorUnits,
 	)
 
 	if err != nil {
@@ -55,7 +75,7 @@ func (m *CurrencyStorage) GetByCode(ctx context.Context, code string) (*Currency
 }
 
 func (m *CurrencyStorage) Get(ctx context.Context, id int64) (*Currency, error) {
-	query := `SELECT id, code, name, symbol_url FROM currencies WHERE id = $1`
+	query := `SELECT id, code, name, symbol_url, minor_units FROM currencies WHERE id = $1`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 	defer cancel()
@@ -67,6 +87,7 @@ func (m *CurrencyStorage) Get(ctx context.Context, id int64) (*Currency, error)
 		&currency.Code,
 		&currency.Name,
 		&currency.SymbolUrl,
+		&currency.MinorUnits,
 	)
 
 	if err != nil {
@@ -85,7 +106,7 @@ func (m *CurrencyStorage) List(ctx context.Context, filter Filter) ([]Currency,
 	var currencies []Currency
 	var totalRecord int
 
-	query := fmt.Sprintf(`SELECT COUNT(*) OVER(), id, code, name, symbol_url FROM currencies
+	query := fmt.Sprintf(`SELECT COUNT(*) OVER(), id, code, name, symbol_url, minor_units FROM currencies
 	WHERE (to_tsvector('simple', name) @@ plainto_tsquery('simple', $1) 
 	   OR code = $1 OR $1 = '')
 	ORDER BY %s %s, id ASC
@@ -105,7 +126,8 @@ func (m *CurrencyStorage) List(ctx context.Context, filter Filter) ([]Currency,
 	for rows.Next() {
 		var currency Currency
 
-		err = rows.Scan(&totalRecord, &currency.ID, &currency.Code, &currency.Name, &currency.SymbolUrl)
+		err = rows.Scan(&totalRecord, &currency.ID, &currency.Code, &currency.Name, &currency.SymbolUrl,
+			&currency.MinorUnits)
 		if err != nil {
 			return nil, Metadata{}, err
 		}
@@ -120,12 +142,12 @@ func (m *CurrencyStorage) List(ctx context.Context, filter Filter) ([]Currency,
 
 func (m *CurrencyStorage) Insert(ctx context.Context, currency *Currency) error {
 	return withTx(ctx, m.db, func(tx *sql.Tx) error {
-		query := `INSERT INTO currencies(code, name, symbol_url) VALUES($1, $2, $3)`
+		query := `INSERT INTO currencies(code, name, symbol_url, minor_units) VALUES($1, $2, $3, $4)`
 
 		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 		defer cancel()
 
-		_, err := tx.ExecContext(ctx, query, currency.Code, currency.Name, currency.SymbolUrl)
+		_, err := tx.ExecContext(ctx, query, currency.Code, currency.Name, currency.SymbolUrl, currency.MinorUnits)
 
 		if err != nil {
 			return err
@@ -137,12 +159,13 @@ func (m *CurrencyStorage) Insert(ctx context.Context, currency *Currency) error
 
 func (m *CurrencyStorage) Update(ctx context.Context, id int64, currency *Currency) error {
 	return withTx(ctx, m.db, func(tx *sql.Tx) error {
-		query := `UPDATE currencies SET code = $1, name = $2, symbol_url = $3 WHERE id = $4`
+		query := `UPDATE currencies SET code = $1, name = $2, symbol_url = $3, minor_units = $4 WHERE id = $5`
 
 		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 		defer cancel()
 
-		_, err := tx.ExecContext(ctx, query, currency.Code, currency.Name, currency.SymbolUrl, id)
+		_, err := tx.ExecContext(ctx, query, currency.Code, currency.Name, currency.SymbolUrl, currency.MinorUnits,
+			id)
 		if err != nil {
 			return err
 		}
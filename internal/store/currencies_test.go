@@ -31,22 +31,22 @@ func TestGetCurrency(t *testing.T) {
 				const id = 1
 
 				// Add a row to mock database
-				rows := sqlmock.NewRows([]string{"id", "code", "name", "symbol_url"}).
-					AddRow(id, "USD", "US Dollar", nil)
+				rows := sqlmock.NewRows([]string{"id", "code", "name", "symbol_url", "minor_units"}).
+					AddRow(id, "USD", "US Dollar", nil, 2)
 
-				mock.ExpectQuery(`SELECT id, code, name, symbol_url FROM currencies WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, code, name, symbol_url, minor_units FROM currencies WHERE id = \$1`).
 					WithArgs(id).
 					WillReturnRows(rows)
 			},
 			expectedError: nil,
-			expectedData:  &Currency{ID: 1, Code: "USD", Name: "US Dollar", SymbolUrl: nil},
+			expectedData:  &Currency{ID: 1, Code: "USD", Name: "US Dollar", SymbolUrl: nil, MinorUnits: 2},
 		},
 		{
 			name: "should return an error not found",
 			id:   3,
 			mockResponse: func() {
 				const id = 3
-				mock.ExpectQuery(`SELECT id, code, name, symbol_url FROM currencies WHERE id = \$1`).
+				mock.ExpectQuery(`SELECT id, code, name, symbol_url, minor_units FROM currencies WHERE id = \$1`).
 					WithArgs(id).
 					WillReturnError(sql.ErrNoRows)
 			},
@@ -101,12 +101,12 @@ func TestCurrencyModel_List(t *testing.T) {
 		{
 			name:       "Found multiple currencies",
 			searchTerm: "USD",
-			mockRows: sqlmock.NewRows([]string{"count", "id", "code", "name", "symbol_url"}).
-				AddRow(2, 1, "USD", "US Dollar", "https://example.com/usd-symbol.png").
-				AddRow(2, 2, "EUR", "Euro", "https://example.com/eur-symbol.png"),
+			mockRows: sqlmock.NewRows([]string{"count", "id", "code", "name", "symbol_url", "minor_units"}).
+				AddRow(2, 1, "USD", "US Dollar", "https://example.com/usd-symbol.png", 2).
+				AddRow(2, 2, "EUR", "Euro", "https://example.com/eur-symbol.png", 2),
 			expectedResult: []Currency{
-				{ID: 1, Code: "USD", Name: "US Dollar", SymbolUrl: ptr("https://example.com/usd-symbol.png")},
-				{ID: 2, Code: "EUR", Name: "Euro", SymbolUrl: ptr("https://example.com/eur-symbol.png")},
+				{ID: 1, Code: "USD", Name: "US Dollar", SymbolUrl: ptr("https://example.com/usd-symbol.png"), MinorUnits: 2},
+				{ID: 2, Code: "EUR", Name: "Euro", SymbolUrl: ptr("https://example.com/eur-symbol.png"), MinorUnits: 2},
 			},
 			expectedMeta: Metadata{
 				CurrentPage: 1,
@@ -120,7 +120,7 @@ func TestCurrencyModel_List(t *testing.T) {
 		{
 			name:           "No currencies found",
 			searchTerm:     "JPY",
-			mockRows:       sqlmock.NewRows([]string{"count", "id", "code", "name", "symbol_url"}), // No results
+			mockRows:       sqlmock.NewRows([]string{"count", "id", "code", "name", "symbol_url", "minor_units"}), // No results
 			expectedResult: []Currency(nil),
 			expectedMeta: Metadata{
 				CurrentPage: 0,
@@ -143,7 +143,7 @@ func TestCurrencyModel_List(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			// Setup mock query expectations
-			query := `SELECT COUNT\(\*\) OVER\(\), id, code, name, symbol_url FROM currencies
+			query := `SELECT COUNT\(\*\) OVER\(\), id, code, name, symbol_url, minor_units FROM currencies
 	WHERE \(to_tsvector\('simple', name\) @@ plainto_tsquery\('simple', \$1\) 
 	OR code = \$1 OR \$1 = ''\)
 	ORDER BY id ASC, id ASC
@@ -198,15 +198,16 @@ func TestInsertCurrency(t *testing.T) {
 
 	// Define the currency you want to insert
 	currency := &Currency{
-		Code:      "USD",
-		Name:      "US Dollar",
-		SymbolUrl: ptr("https://example.com/usd-symbol.png"),
+		Code:       "USD",
+		Name:       "US Dollar",
+		SymbolUrl:  ptr("https://example.com/usd-symbol.png"),
+		MinorUnits: 2,
 	}
 
 	// Mock successful query
 	mock.ExpectBegin()
 	mock.ExpectExec(`INSERT INTO currencies`).
-		WithArgs(currency.Code, currency.Name, currency.SymbolUrl).
+		WithArgs(currency.Code, currency.Name, currency.SymbolUrl, currency.MinorUnits).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -228,9 +229,10 @@ func TestUpdateCurrency(t *testing.T) {
 
 	// Define the currency you want to update
 	currency := &Currency{
-		Code:      "USD",
-		Name:      "US Dollar updated",
-		SymbolUrl: ptr("https://example.com/usd-symbol.png"),
+		Code:       "USD",
+		Name:       "US Dollar updated",
+		SymbolUrl:  ptr("https://example.com/usd-symbol.png"),
+		MinorUnits: 2,
 	}
 
 	id := int64(1)
@@ -238,7 +240,7 @@ func TestUpdateCurrency(t *testing.T) {
 	// Mock successful update
 	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE currencies SET").
-		WithArgs(currency.Code, currency.Name, currency.SymbolUrl, id).
+		WithArgs(currency.Code, currency.Name, currency.SymbolUrl, currency.MinorUnits, id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -263,9 +265,10 @@ func TestUpdateCurrencyNotFound(t *testing.T) {
 
 	// Define the currency to update
 	currency := &Currency{
-		Code:      "USD",
-		Name:      "US Dollar",
-		SymbolUrl: ptr("https://example.com/usd-symbol.png"),
+		Code:       "USD",
+		Name:       "US Dollar",
+		SymbolUrl:  ptr("https://example.com/usd-symbol.png"),
+		MinorUnits: 2,
 	}
 
 	id := int64(1)
@@ -273,7 +276,7 @@ func TestUpdateCurrencyNotFound(t *testing.T) {
 	// Mock transaction and query execution
 	mock.ExpectBegin()
 	mock.ExpectExec("UPDATE currencies SET").
-		WithArgs(currency.Code, currency.Name, currency.SymbolUrl, id).
+		WithArgs(currency.Code, currency.Name, currency.SymbolUrl, currency.MinorUnits, id).
 		WillReturnError(ErrNotFound) // 0 rows affected
 	mock.ExpectRollback() // Expect a rollback since no rows were found
 
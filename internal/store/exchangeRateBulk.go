@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+// SourceImport marks a rate loaded from a bulk import
+const SourceImport = "import"
+
+// Import records every rate as an observation and makes it the current rate of
+// its pair unless the pair holds a newer rate or an active manual override,
+// all in a single transaction. The number of pairs whose current rate changed
+// is returned.
+func (s *ExchangeRateStorage) Import(ctx context.Context, rates []ExchangeRate) (int, error) {
+	var current []RateObservation
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	INSERT INTO exchange_rates AS er(base_code, target_code, rate, bid, ask, last_update, next_update, sources,
+	                                 spread, source)
+	VALUES($1, $2, $3, $4, $5, $6, $7, '{}', 0, $8)
+	ON CONFLICT (base_code, target_code) DO UPDATE
+	SET rate = EXCLUDED.rate, bid = EXCLUDED.bid, ask = EXCLUDED.ask,
+	    last_update = EXCLUDED.last_update, next_update = EXCLUDED.next_update,
+	    sources = EXCLUDED.sources, spread = EXCLUDED.spread, source = EXCLUDED.source,
+	    set_by = NULL, override_expires_at = NULL
+	WHERE NOT ` + activeOverride + ` AND er.last_update <= EXCLUDED.last_update
+	RETURNING id`
+
+		ctx, cancel := context.WithTimeout(ctx, BulkContextTimeout)
+		defer cancel()
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i := range rates {
+			rate := &rates[i]
+			rate.Source = SourceImport
+			rate.Sources = []string{}
+			rate.Spread = 0
+
+			if err = applyMarkup(ctx, tx, rate); err != nil {
+				return err
+			}
+
+			args := []any{rate.BaseCode, rate.TargetCode, rate.Rate, rate.Bid, rate.Ask, rate.LastUpdate,
+				rate.NextUpdate, rate.Source}
+
+			updated := true
+			err = stmt.QueryRowContext(ctx, args...).Scan(&rate.ID)
+			if err != nil {
+				if !errors.Is(err, sql.ErrNoRows) {
+					return err
+				}
+				updated = false
+			}
+
+			// Older rates still fill in the history of the pair
+			observation, err := insertHistory(ctx, tx, rate)
+			if err != nil {
+				return err
+			}
+
+			if updated {
+				current = append(current, observation)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.notify(current...)
+
+	return len(current), nil
+}
+
+// Walk calls fn with every stored pair, optionally restricted to a base and
+// target, without loading them all in memory. An error from fn stops the walk.
+func (s *ExchangeRateStorage) Walk(ctx context.Context, base, target string, fn func(*ExchangeRate) error) error {
+	query := `
+	SELECT ` + exchangeRateColumns + `
+	FROM exchange_rates er
+	WHERE ($1 = '' OR er.base_code = $1) AND ($2 = '' OR er.target_code = $2)
+	ORDER BY er.base_code, er.target_code`
+
+	ctx, cancel := context.WithTimeout(ctx, BulkContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, base, target)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate ExchangeRate
+
+		if err = scanExchangeRate(rows, &rate); err != nil {
+			return err
+		}
+
+		if err = fn(&rate); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// WalkHistory calls fn with every observation between from and to, oldest
+// first. A zero time leaves the bound open, an empty base or target matches
+// every pair.
+func (s *ExchangeRateStorage) WalkHistory(ctx context.Context, base, target string, from, to time.Time,
+	fn func(*RateObservation) error) error {
+	query := `
+	SELECT id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+	FROM exchange_rate_history
+	WHERE ($1 = '' OR base_code = $1) AND ($2 = '' OR target_code = $2)
+	  AND ($3::timestamp IS NULL OR observed_at >= $3)
+	  AND ($4::timestamp IS NULL OR observed_at <= $4)
+	ORDER BY observed_at, id`
+
+	ctx, cancel := context.WithTimeout(ctx, BulkContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, base, target, nullTime(from), nullTime(to))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o RateObservation
+
+		err = rows.Scan(&o.ID, &o.BaseCode, &o.TargetCode, &o.Rate, &o.Bid, &o.Ask, &o.Spread,
+			pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+		if err != nil {
+			return err
+		}
+
+		if err = fn(&o); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,259 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"time"
+)
+
+// RateObservation is one append-only entry of a pair's rate history
+type RateObservation struct {
+	ID         int64           `json:"id"`
+	BaseCode   string          `json:"base_code"`
+	TargetCode string          `json:"target_code"`
+	Rate       decimal.Decimal `json:"rate"`
+	Bid        decimal.Decimal `json:"bid"`
+	Ask        decimal.Decimal `json:"ask"`
+	Spread     float64         `json:"spread"`
+	Sources    []string        `json:"sources"`
+	Source     string          `json:"source"`
+	ObservedAt time.Time       `json:"observed_at"`
+	RecordedAt time.Time       `json:"recorded_at"`
+}
+
+// insertHistory appends the current state of rate to exchange_rate_history
+func insertHistory(ctx context.Context, tx *sql.Tx, rate *ExchangeRate) (RateObservation, error) {
+	query := `
+	INSERT INTO exchange_rate_history(base_code, target_code, rate, bid, ask, spread, sources, source, observed_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, recorded_at`
+
+	o := RateObservation{
+		BaseCode:   rate.BaseCode,
+		TargetCode: rate.TargetCode,
+		Rate:       rate.Rate,
+		Bid:        rate.Bid,
+		Ask:        rate.Ask,
+		Spread:     rate.Spread,
+		Sources:    rate.Sources,
+		Source:     rate.Source,
+		ObservedAt: rate.LastUpdate,
+	}
+
+	args := []any{o.BaseCode, o.TargetCode, o.Rate, o.Bid, o.Ask, o.Spread, pq.Array(o.Sources), o.Source,
+		o.ObservedAt}
+
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&o.ID, &o.RecordedAt)
+
+	return o, err
+}
+
+// History lists the observations of a pair between from and to, a zero time leaves the bound open
+func (s *ExchangeRateStorage) History(ctx context.Context, base, target string, from, to time.Time,
+	filter Filter) ([]RateObservation, Metadata, error) {
+	var observations []RateObservation
+	var totalRecord int
+
+	query := fmt.Sprintf(`
+	SELECT COUNT(*) OVER(), id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+	FROM exchange_rate_history
+	WHERE base_code = $1 AND target_code = $2
+	  AND ($3::timestamp IS NULL OR observed_at >= $3)
+	  AND ($4::timestamp IS NULL OR observed_at <= $4)
+	ORDER BY %s %s, id ASC
+	LIMIT $5 OFFSET $6`, filter.sortColumn(), filter.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{base, target, nullTime(from), nullTime(to), filter.limit(), filter.calculateOffset()}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o RateObservation
+
+		err = rows.Scan(&totalRecord, &o.ID, &o.BaseCode, &o.TargetCode, &o.Rate, &o.Bid, &o.Ask, &o.Spread,
+			pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		observations = append(observations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return observations, filter.calculateMetadata(totalRecord), nil
+}
+
+// GetAt returns the observation of the pair that was in effect at the given instant
+func (s *ExchangeRateStorage) GetAt(ctx context.Context, base, target string, at time.Time) (*RateObservation, error) {
+	var o RateObservation
+
+	query := `
+	SELECT id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+	FROM exchange_rate_history
+	WHERE base_code = $1 AND target_code = $2 AND observed_at <= $3
+	ORDER BY observed_at DESC, id DESC
+	LIMIT 1`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, base, target, at).Scan(&o.ID, &o.BaseCode, &o.TargetCode, &o.Rate,
+		&o.Bid, &o.Ask, &o.Spread, pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, fmt.Errorf("%w: no %s/%s rate at or before %s", ErrNotFound, base, target, at.Format(time.RFC3339))
+		default:
+			return nil, err
+		}
+	}
+
+	return &o, nil
+}
+
+// AllAt returns, for every pair, the observation that was in effect at the given instant
+func (s *ExchangeRateStorage) AllAt(ctx context.Context, at time.Time) ([]RateObservation, error) {
+	var observations []RateObservation
+
+	query := `
+	SELECT DISTINCT ON (base_code, target_code)
+	       id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+	FROM exchange_rate_history
+	WHERE observed_at <= $1
+	ORDER BY base_code, target_code, observed_at DESC, id DESC`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, at)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o RateObservation
+
+		err = rows.Scan(&o.ID, &o.BaseCode, &o.TargetCode, &o.Rate, &o.Bid, &o.Ask, &o.Spread,
+			pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		observations = append(observations, o)
+	}
+
+	return observations, rows.Err()
+}
+
+// Series returns the latest limit observations of a pair between from and to, oldest first
+func (s *ExchangeRateStorage) Series(ctx context.Context, base, target string, from, to time.Time,
+	limit int) ([]RateObservation, error) {
+	var observations []RateObservation
+
+	query := `
+	SELECT * FROM (
+		SELECT id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+		FROM exchange_rate_history
+		WHERE base_code = $1 AND target_code = $2 AND observed_at >= $3 AND observed_at <= $4
+		ORDER BY observed_at DESC, id DESC
+		LIMIT $5
+	) latest
+	ORDER BY observed_at, id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, base, target, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o RateObservation
+
+		err = rows.Scan(&o.ID, &o.BaseCode, &o.TargetCode, &o.Rate, &o.Bid, &o.Ask, &o.Spread,
+			pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		observations = append(observations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+// Since returns up to limit observations of the given pairs recorded after the observation afterID, oldest first.
+// Ids are taken on insert but become visible on commit, so a lower id may appear after afterID was read:
+// observations with a lower id recorded at most lookback before afterID are returned again.
+func (s *ExchangeRateStorage) Since(ctx context.Context, afterID int64, lookback time.Duration, pairs []Pair,
+	limit int) ([]RateObservation, error) {
+	var observations []RateObservation
+
+	query := `
+	SELECT id, base_code, target_code, rate, bid, ask, spread, sources, source, observed_at, recorded_at
+	FROM exchange_rate_history
+	WHERE (id > $1 OR recorded_at >= (SELECT recorded_at FROM exchange_rate_history WHERE id = $1)
+		- $4::float8 * INTERVAL '1 second')
+	AND (base_code, target_code) IN (SELECT * FROM unnest($2::varchar[], $3::varchar[]))
+	ORDER BY id
+	LIMIT $5`
+
+	bases := make([]string, len(pairs))
+	targets := make([]string, len(pairs))
+	for i, pair := range pairs {
+		bases[i], targets[i] = pair.BaseCode, pair.TargetCode
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, afterID, pq.Array(bases), pq.Array(targets), lookback.Seconds(),
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var o RateObservation
+
+		err = rows.Scan(&o.ID, &o.BaseCode, &o.TargetCode, &o.Rate, &o.Bid, &o.Ask, &o.Spread,
+			pq.Array(&o.Sources), &o.Source, &o.ObservedAt, &o.RecordedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		observations = append(observations, o)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return observations, nil
+}
+
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
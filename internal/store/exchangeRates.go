@@ -4,32 +4,137 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"github.com/lib/pq"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"sync"
 	"time"
 )
 
+// SourceManual marks a rate set by hand instead of fetched from a provider
+const SourceManual = "manual"
+
+// NeverUpdate is the next update of a manual rate without expiry, which is
+// neither refreshed nor ever stale
+var NeverUpdate = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
 type IExchangeRates interface {
 	GetByPair(ctx context.Context, base, target string) (*ExchangeRate, error)
+	List(ctx context.Context, base, target string, stale *bool, filter Filter) ([]ExchangeRate, Metadata, error)
+	All(ctx context.Context) ([]ExchangeRate, error)
 	Save(ctx context.Context, rate *ExchangeRate) error
 	Update(ctx context.Context, rate *ExchangeRate) error
+	Refresh(ctx context.Context, rate *ExchangeRate) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]ExchangeRate, error)
+	UpsertMany(ctx context.Context, rates []ExchangeRate) (int, error)
+	Override(ctx context.Context, rate *ExchangeRate) error
+	Import(ctx context.Context, rates []ExchangeRate) (int, error)
+	Walk(ctx context.Context, base, target string, fn func(*ExchangeRate) error) error
+	WalkHistory(ctx context.Context, base, target string, from, to time.Time, fn func(*RateObservation) error) error
+	History(ctx context.Context, base, target string, from, to time.Time, filter Filter) ([]RateObservation, Metadata, error)
+	GetAt(ctx context.Context, base, target string, at time.Time) (*RateObservation, error)
+	AllAt(ctx context.Context, at time.Time) ([]RateObservation, error)
+	Series(ctx context.Context, base, target string, from, to time.Time, limit int) ([]RateObservation, error)
+	Since(ctx context.Context, afterID int64, lookback time.Duration, pairs []Pair, limit int) ([]RateObservation, error)
+	Candles(ctx context.Context, base, target, interval string, from, to time.Time, limit int) ([]Candle, error)
+	Subscribe(listener RateListener)
+}
+
+// RateListener is called with the observation recorded for every written
+// rate, once its transaction has committed
+type RateListener func(observation RateObservation)
+
+// Pair identifies a currency pair
+type Pair struct {
+	BaseCode   string `json:"base_code"`
+	TargetCode string `json:"target_code"`
 }
 
 type ExchangeRate struct {
-	ID         int64     `json:"id"`
-	LastUpdate time.Time `json:"last_update"`
-	NextUpdate time.Time `json:"next_update"`
-	BaseCode   string    `json:"base_code"`
-	TargetCode string    `json:"target_code"`
-	Rate       float64   `json:"rate"`
+	ID         int64           `json:"id"`
+	LastUpdate time.Time       `json:"last_update"`
+	NextUpdate time.Time       `json:"next_update"`
+	BaseCode   string          `json:"base_code"`
+	TargetCode string          `json:"target_code"`
+	Rate       decimal.Decimal `json:"rate"`
+	// Bid and Ask are the mid Rate moved by the pair's markup
+	Bid     decimal.Decimal `json:"bid"`
+	Ask     decimal.Decimal `json:"ask"`
+	Sources []string        `json:"sources"`
+	Spread  float64         `json:"spread"`
+	// Source lists the providers that answered, comma separated, or is SourceManual
+	Source string `json:"source"`
+	// SetBy is the user who set a manual rate
+	SetBy *int64 `json:"set_by,omitempty"`
+	// OverrideExpiresAt ends a manual rate, nil keeps it until removed
+	OverrideExpiresAt *time.Time `json:"override_expires_at,omitempty"`
+}
+
+// OverrideActive reports whether a manual rate currently shields the pair from refreshes
+func (r *ExchangeRate) OverrideActive(now time.Time) bool {
+	return r.Source == SourceManual && (r.OverrideExpiresAt == nil || r.OverrideExpiresAt.After(now))
 }
 
 type ExchangeRateStorage struct {
 	db *sql.DB
+
+	mu        sync.RWMutex
+	listeners []RateListener
+}
+
+// Subscribe registers a listener for rates written by Save, Update, UpsertMany, Override and Import
+func (s *ExchangeRateStorage) Subscribe(listener RateListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+func (s *ExchangeRateStorage) notify(observations ...RateObservation) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, observation := range observations {
+		for _, listener := range s.listeners {
+			listener(observation)
+		}
+	}
+}
+
+const exchangeRateColumns = `er.id, er.rate, er.bid, er.ask, er.last_update, er.next_update, er.base_code,
+	er.target_code, er.sources, er.spread, er.source, er.set_by, er.override_expires_at`
+
+// activeOverride matches rows holding a manual rate that has not expired
+const activeOverride = `(er.source = 'manual' AND
+	(er.override_expires_at IS NULL OR er.override_expires_at > NOW() AT TIME ZONE 'UTC'))`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanExchangeRate reads exchangeRateColumns, prefix receives columns selected before them
+func scanExchangeRate(row rowScanner, rate *ExchangeRate, prefix ...any) error {
+	return row.Scan(append(prefix,
+		&rate.ID,
+		&rate.Rate,
+		&rate.Bid,
+		&rate.Ask,
+		&rate.LastUpdate,
+		&rate.NextUpdate,
+		&rate.BaseCode,
+		&rate.TargetCode,
+		pq.Array(&rate.Sources),
+		&rate.Spread,
+		&rate.Source,
+		&rate.SetBy,
+		&rate.OverrideExpiresAt,
+	)...)
 }
 
 func (s *ExchangeRateStorage) GetByPair(ctx context.Context, base, target string) (*ExchangeRate, error) {
 	var rate ExchangeRate
 	query := `
-	SELECT er.id, rate, last_update, next_update, base.code AS base_code, target.code AS target_code
+	SELECT ` + exchangeRateColumns + `
 	FROM exchange_rates er
 	INNER JOIN currencies base ON base.code = er.base_code
 	INNER JOIN currencies target ON target.code = er.target_code
@@ -44,14 +149,7 @@ func (s *ExchangeRateStorage) GetByPair(ctx context.Context, base, target string
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRowContext(ctx, base, target).Scan(
-		&rate.ID,
-		&rate.Rate,
-		&rate.LastUpdate,
-		&rate.NextUpdate,
-		&rate.BaseCode,
-		&rate.TargetCode,
-	)
+	err = scanExchangeRate(stmt.QueryRowContext(ctx, base, target), &rate)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -64,45 +162,339 @@ func (s *ExchangeRateStorage) GetByPair(ctx context.Context, base, target string
 	return &rate, nil
 }
 
+// List returns the stored pairs. Empty codes and a nil stale match every
+// pair, a pair is stale once its next update has passed.
+func (s *ExchangeRateStorage) List(ctx context.Context, base, target string, stale *bool,
+	filter Filter) ([]ExchangeRate, Metadata, error) {
+	var rates []ExchangeRate
+	var totalRecord int
+
+	query := fmt.Sprintf(`
+	SELECT COUNT(*) OVER(), `+exchangeRateColumns+`
+	FROM exchange_rates er
+	WHERE (er.base_code = $1 OR $1 = '')
+	  AND (er.target_code = $2 OR $2 = '')
+	  AND ($3::boolean IS NULL OR (er.next_update < NOW() AT TIME ZONE 'UTC') = $3)
+	ORDER BY er.%s %s, er.id ASC
+	LIMIT $4 OFFSET $5`, filter.sortColumn(), filter.sortDirection())
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{base, target, stale, filter.limit(), filter.calculateOffset()}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate ExchangeRate
+
+		err = scanExchangeRate(rows, &rate, &totalRecord)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return rates, filter.calculateMetadata(totalRecord), nil
+}
+
+// All returns every stored pair, used to derive rates for pairs that are not stored
+func (s *ExchangeRateStorage) All(ctx context.Context) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+
+	query := `SELECT ` + exchangeRateColumns + ` FROM exchange_rates er ORDER BY er.id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate ExchangeRate
+
+		if err = scanExchangeRate(rows, &rate); err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
 func (s *ExchangeRateStorage) Save(ctx context.Context, rate *ExchangeRate) error {
-	query := `
-	INSERT INTO exchange_rates(base_code, target_code, rate, last_update, next_update)
-	VALUES($1, $2, $3, $4, $5)
+	var observation RateObservation
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	INSERT INTO exchange_rates(base_code, target_code, rate, bid, ask, last_update, next_update, sources, spread,
+	                           source)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	RETURNING id
 	`
 
-	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
-	defer cancel()
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		if err := applyMarkup(ctx, tx, rate); err != nil {
+			return err
+		}
 
-	args := []any{rate.BaseCode, rate.TargetCode, rate.Rate, rate.LastUpdate, rate.NextUpdate}
+		args := []any{rate.BaseCode, rate.TargetCode, rate.Rate, rate.Bid, rate.Ask, rate.LastUpdate, rate.NextUpdate,
+			pq.Array(rate.Sources), rate.Spread, rate.Source}
 
-	err := s.db.QueryRowContext(ctx, query, args...).Scan(&rate.ID)
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&rate.ID)
+		if err != nil {
+			return err
+		}
+
+		observation, err = insertHistory(ctx, tx, rate)
+		return err
+	})
 	if err != nil {
 		return err
 	}
 
+	s.notify(observation)
+
 	return nil
 }
 
 func (s *ExchangeRateStorage) Update(ctx context.Context, rate *ExchangeRate) error {
-	query := `
-	UPDATE exchange_rates r
-	SET	rate = $1
-	WHERE r.base_code = $2 AND r.target_code = $3
+	return s.update(ctx, rate, false)
+}
+
+// Refresh is Update for provider rates. It returns ErrOverrideActive instead
+// of replacing a manual rate set after the pair was read.
+func (s *ExchangeRateStorage) Refresh(ctx context.Context, rate *ExchangeRate) error {
+	return s.update(ctx, rate, true)
+}
+
+func (s *ExchangeRateStorage) update(ctx context.Context, rate *ExchangeRate, keepOverride bool) error {
+	var observation RateObservation
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	UPDATE exchange_rates er
+	SET	rate = $1, last_update = $2, next_update = $3, sources = $4, spread = $5,
+		source = $6, set_by = $7, override_expires_at = $8, bid = $9, ask = $10
+	WHERE er.base_code = $11 AND er.target_code = $12
 `
+		if keepOverride {
+			query += `	AND NOT ` + activeOverride
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		if err := applyMarkup(ctx, tx, rate); err != nil {
+			return err
+		}
+
+		args := []any{rate.Rate, rate.LastUpdate, rate.NextUpdate, pq.Array(rate.Sources), rate.Spread,
+			rate.Source, rate.SetBy, rate.OverrideExpiresAt, rate.Bid, rate.Ask, rate.BaseCode, rate.TargetCode}
+
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if keepOverride && affected == 0 {
+			return ErrOverrideActive
+		}
+
+		observation, err = insertHistory(ctx, tx, rate)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(observation)
+
+	return nil
+}
+
+// ListDue returns up to limit pairs whose next update is at or before now,
+// most overdue first. Pairs under an active manual override are left out.
+func (s *ExchangeRateStorage) ListDue(ctx context.Context, now time.Time, limit int) ([]ExchangeRate, error) {
+	var rates []ExchangeRate
+
+	query := `
+	SELECT ` + exchangeRateColumns + `
+	FROM exchange_rates er
+	WHERE er.next_update <= $1 AND NOT ` + activeOverride + `
+	ORDER BY er.next_update ASC
+	LIMIT $2`
 
 	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 	defer cancel()
 
-	_, err := s.db.ExecContext(ctx, query, rate.Rate, rate.BaseCode, rate.TargetCode)
+	rows, err := s.db.QueryContext(ctx, query, now, limit)
 	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
-		default:
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rate ExchangeRate
+
+		if err = scanExchangeRate(rows, &rate); err != nil {
+			return nil, err
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return rates, rows.Err()
+}
+
+// UpsertMany inserts or updates every rate in a single transaction. Pairs
+// referencing an unknown currency or under an active manual override are
+// skipped, the number of written pairs is returned.
+func (s *ExchangeRateStorage) UpsertMany(ctx context.Context, rates []ExchangeRate) (int, error) {
+	var written []RateObservation
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	INSERT INTO exchange_rates AS er(base_code, target_code, rate, last_update, next_update, sources, spread, source,
+	                                 bid, ask)
+	SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+	WHERE EXISTS (SELECT 1 FROM currencies WHERE code = $1)
+	  AND EXISTS (SELECT 1 FROM currencies WHERE code = $2)
+	ON CONFLICT (base_code, target_code) DO UPDATE
+	SET rate = EXCLUDED.rate, bid = EXCLUDED.bid, ask = EXCLUDED.ask,
+	    last_update = EXCLUDED.last_update, next_update = EXCLUDED.next_update,
+	    sources = EXCLUDED.sources, spread = EXCLUDED.spread, source = EXCLUDED.source,
+	    set_by = NULL, override_expires_at = NULL
+	WHERE NOT ` + activeOverride + `
+	RETURNING id`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		stmt, err := tx.PrepareContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i := range rates {
+			rate := &rates[i]
+			if err = applyMarkup(ctx, tx, rate); err != nil {
+				return err
+			}
+
+			args := []any{rate.BaseCode, rate.TargetCode, rate.Rate, rate.LastUpdate, rate.NextUpdate,
+				pq.Array(rate.Sources), rate.Spread, rate.Source, rate.Bid, rate.Ask}
+
+			err = stmt.QueryRowContext(ctx, args...).Scan(&rate.ID)
+			if err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				return err
+			}
+
+			observation, err := insertHistory(ctx, tx, rate)
+			if err != nil {
+				return err
+			}
+			written = append(written, observation)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.notify(written...)
+
+	return len(written), nil
+}
+
+// Override stores a manual rate for the pair, creating the pair when missing
+func (s *ExchangeRateStorage) Override(ctx context.Context, rate *ExchangeRate) error {
+	var observation RateObservation
+
+	err := withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	INSERT INTO exchange_rates AS er(base_code, target_code, rate, last_update, next_update, sources, spread,
+	                                 source, set_by, override_expires_at, bid, ask)
+	VALUES($1, $2, $3, $4, $5, '{}', 0, $6, $7, $8, $9, $10)
+	ON CONFLICT (base_code, target_code) DO UPDATE
+	SET rate = EXCLUDED.rate, bid = EXCLUDED.bid, ask = EXCLUDED.ask,
+	    last_update = EXCLUDED.last_update, next_update = EXCLUDED.next_update,
+	    sources = EXCLUDED.sources, spread = EXCLUDED.spread, source = EXCLUDED.source,
+	    set_by = EXCLUDED.set_by, override_expires_at = EXCLUDED.override_expires_at
+	RETURNING id`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		rate.Source = SourceManual
+		rate.Sources = []string{}
+		rate.Spread = 0
+
+		if err := applyMarkup(ctx, tx, rate); err != nil {
 			return err
 		}
+
+		args := []any{rate.BaseCode, rate.TargetCode, rate.Rate, rate.LastUpdate, rate.NextUpdate,
+			rate.Source, rate.SetBy, rate.OverrideExpiresAt, rate.Bid, rate.Ask}
+
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&rate.ID)
+		if err != nil {
+			return err
+		}
+
+		observation, err = insertHistory(ctx, tx, rate)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notify(observation)
+
+	return nil
+}
+
+// applyMarkup prices the bid and ask of rate with the markup in effect for its pair
+func applyMarkup(ctx context.Context, tx *sql.Tx, rate *ExchangeRate) error {
+	markup, err := effectiveMarkup(ctx, tx, rate.BaseCode, rate.TargetCode)
+	if err != nil {
+		return err
 	}
 
+	markup.Apply(rate)
+
 	return nil
 }
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"time"
+)
+
+type IMarkups interface {
+	List(ctx context.Context) ([]Markup, error)
+	Set(ctx context.Context, markup *Markup) error
+	Delete(ctx context.Context, base, target string) error
+}
+
+// Markup moves the bid below and the ask above the mid rate, in basis points.
+// A markup without a pair is the default of pairs that have none.
+type Markup struct {
+	BaseCode   string    `json:"base_code,omitempty"`
+	TargetCode string    `json:"target_code,omitempty"`
+	BidBps     float64   `json:"bid_bps"`
+	AskBps     float64   `json:"ask_bps"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// bps is one basis point
+var bps = decimal.New(1, 4)
+
+// Apply prices the bid and ask of rate from its mid, rounded to the stored scale
+func (m Markup) Apply(rate *ExchangeRate) {
+	bid := decimal.One.Sub(decimal.NewFromFloat(m.BidBps).Mul(bps))
+	ask := decimal.One.Add(decimal.NewFromFloat(m.AskBps).Mul(bps))
+
+	rate.Bid = rate.Rate.Mul(bid).Round(decimal.Scale)
+	rate.Ask = rate.Rate.Mul(ask).Round(decimal.Scale)
+}
+
+type MarkupStorage struct {
+	db *sql.DB
+}
+
+func (s *MarkupStorage) List(ctx context.Context) ([]Markup, error) {
+	var markups []Markup
+
+	query := `
+	SELECT COALESCE(base_code, ''), COALESCE(target_code, ''), bid_bps, ask_bps, updated_at
+	FROM rate_markups
+	ORDER BY base_code NULLS FIRST, target_code`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m Markup
+
+		if err = rows.Scan(&m.BaseCode, &m.TargetCode, &m.BidBps, &m.AskBps, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		markups = append(markups, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return markups, nil
+}
+
+// Set creates or replaces the markup of a pair, or the default one when the
+// pair is empty, and reprices the stored pairs it applies to.
+func (s *MarkupStorage) Set(ctx context.Context, markup *Markup) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `
+	INSERT INTO rate_markups(base_code, target_code, bid_bps, ask_bps, updated_at)
+	VALUES(NULLIF($1, ''), NULLIF($2, ''), $3, $4, NOW())
+	ON CONFLICT (COALESCE(base_code, ''), COALESCE(target_code, '')) DO UPDATE
+	SET bid_bps = EXCLUDED.bid_bps, ask_bps = EXCLUDED.ask_bps, updated_at = EXCLUDED.updated_at
+	RETURNING updated_at`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		args := []any{markup.BaseCode, markup.TargetCode, markup.BidBps, markup.AskBps}
+
+		err := tx.QueryRowContext(ctx, query, args...).Scan(&markup.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return repriceRates(ctx, tx, markup.BaseCode, markup.TargetCode)
+	})
+}
+
+// Delete removes the markup of a pair, which falls back to the default
+func (s *MarkupStorage) Delete(ctx context.Context, base, target string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		query := `DELETE FROM rate_markups WHERE base_code = $1 AND target_code = $2`
+
+		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+		defer cancel()
+
+		result, err := tx.ExecContext(ctx, query, base, target)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return fmt.Errorf("%w: markup %s/%s", ErrNotFound, base, target)
+		}
+
+		return repriceRates(ctx, tx, base, target)
+	})
+}
+
+// effectiveMarkup returns the markup of the pair, else the default one, else none
+func effectiveMarkup(ctx context.Context, tx *sql.Tx, base, target string) (Markup, error) {
+	m := Markup{BaseCode: base, TargetCode: target}
+
+	query := `
+	SELECT COALESCE(p.bid_bps, d.bid_bps, 0), COALESCE(p.ask_bps, d.ask_bps, 0)
+	FROM (SELECT 1) one
+	LEFT JOIN rate_markups p ON p.base_code = $1 AND p.target_code = $2
+	LEFT JOIN rate_markups d ON d.base_code IS NULL`
+
+	err := tx.QueryRowContext(ctx, query, base, target).Scan(&m.BidBps, &m.AskBps)
+
+	return m, err
+}
+
+// repriceRates recomputes the bid and ask of a stored pair, or of every pair when base is empty
+func repriceRates(ctx context.Context, tx *sql.Tx, base, target string) error {
+	query := `
+	UPDATE exchange_rates er
+	SET bid = er.rate * (1 - COALESCE(p.bid_bps, d.bid_bps, 0) / 10000),
+	    ask = er.rate * (1 + COALESCE(p.ask_bps, d.ask_bps, 0) / 10000)
+	FROM exchange_rates r
+	LEFT JOIN rate_markups p ON p.base_code = r.base_code AND p.target_code = r.target_code
+	LEFT JOIN rate_markups d ON d.base_code IS NULL
+	WHERE er.id = r.id AND ($1 = '' OR (er.base_code = $1 AND er.target_code = $2))`
+
+	_, err := tx.ExecContext(ctx, query, base, target)
+
+	return err
+}
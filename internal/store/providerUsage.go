@@ -0,0 +1,136 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type IProviderUsage interface {
+	Reserve(ctx context.Context, provider string, at time.Time, daily, monthly int) (bool, error)
+	Usage(ctx context.Context, provider string, at time.Time) (daily, monthly int, err error)
+	List(ctx context.Context, at time.Time) ([]ProviderUsage, error)
+}
+
+// ProviderUsage counts the upstream calls made to a provider
+type ProviderUsage struct {
+	Provider string `json:"provider"`
+	Daily    int    `json:"daily"`
+	Monthly  int    `json:"monthly"`
+}
+
+type ProviderUsageStorage struct {
+	db *sql.DB
+}
+
+// Reserve records one call made at the given time unless the provider has
+// already made daily calls that day or monthly calls that month, zero limits
+// are unlimited. Days are counted in UTC. The limit is checked by the upsert
+// itself, so concurrent callers cannot overshoot it.
+func (s *ProviderUsageStorage) Reserve(ctx context.Context, provider string, at time.Time,
+	daily, monthly int) (bool, error) {
+	day := usageDay(at)
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	// Earlier days of the month no longer change, what is left of the monthly
+	// budget caps today's calls
+	limit := daily
+	if monthly > 0 {
+		var previous int
+
+		query := `
+		SELECT COALESCE(SUM(calls), 0)
+		FROM provider_usage
+		WHERE provider = $1 AND day >= date_trunc('month', $2::date) AND day < $2`
+
+		if err := s.db.QueryRowContext(ctx, query, provider, day).Scan(&previous); err != nil {
+			return false, err
+		}
+
+		if remaining := monthly - previous; limit == 0 || remaining < limit {
+			limit = remaining
+		}
+
+		if limit <= 0 {
+			return false, nil
+		}
+	}
+
+	query := `
+	INSERT INTO provider_usage AS pu(provider, day, calls) VALUES($1, $2, 1)
+	ON CONFLICT (provider, day) DO UPDATE SET calls = pu.calls + 1
+	WHERE $3 = 0 OR pu.calls < $3
+	RETURNING calls`
+
+	var calls int
+
+	err := s.db.QueryRowContext(ctx, query, provider, day, limit).Scan(&calls)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Usage returns the calls made on the day and in the month of at
+func (s *ProviderUsageStorage) Usage(ctx context.Context, provider string, at time.Time) (int, int, error) {
+	var daily, monthly int
+
+	query := `
+	SELECT COALESCE(SUM(calls) FILTER (WHERE day = $2), 0), COALESCE(SUM(calls), 0)
+	FROM provider_usage
+	WHERE provider = $1 AND day >= date_trunc('month', $2::date) AND day <= $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, provider, usageDay(at)).Scan(&daily, &monthly)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return daily, monthly, nil
+}
+
+// List returns the usage of every provider called in the month of at
+func (s *ProviderUsageStorage) List(ctx context.Context, at time.Time) ([]ProviderUsage, error) {
+	var usages []ProviderUsage
+
+	query := `
+	SELECT provider, COALESCE(SUM(calls) FILTER (WHERE day = $1), 0), SUM(calls)
+	FROM provider_usage
+	WHERE day >= date_trunc('month', $1::date) AND day <= $1
+	GROUP BY provider
+	ORDER BY provider`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, usageDay(at))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var usage ProviderUsage
+
+		if err = rows.Scan(&usage.Provider, &usage.Daily, &usage.Monthly); err != nil {
+			return nil, err
+		}
+
+		usages = append(usages, usage)
+	}
+
+	return usages, rows.Err()
+}
+
+func usageDay(at time.Time) string {
+	return at.UTC().Format(time.DateOnly)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -11,10 +12,14 @@ var (
 	ErrNotFound     = errors.New("not found record")
 	ErrConflict     = errors.New("record already exists")
 	ErrUnauthorized = errors.New("invalid credentials")
+	// ErrOverrideActive is returned when a provider rate would replace an active manual rate
+	ErrOverrideActive = errors.New("the pair has an active manual rate")
 )
 
 var (
 	QueryContextTimeout = 3 * time.Second
+	// BulkContextTimeout bounds imports and exports that touch every row of a table
+	BulkContextTimeout = time.Minute
 )
 
 type Storage struct {
@@ -22,6 +27,10 @@ type Storage struct {
 	Users        IUsers
 	Rates        IExchangeRates
 	Transactions ITransaction
+	Usage        IProviderUsage
+	Alerts       IAlerts
+	Webhooks     IWebhooks
+	Markups      IMarkups
 }
 
 func NewStorage(db *sql.DB) *Storage {
@@ -30,9 +39,27 @@ func NewStorage(db *sql.DB) *Storage {
 		Users:        &UserStorage{db: db},
 		Rates:        &ExchangeRateStorage{db: db},
 		Transactions: &TransactionStorage{db: db},
+		Usage:        &ProviderUsageStorage{db: db},
+		Alerts:       &AlertStorage{db: db},
+		Webhooks:     &WebhookStorage{db: db},
+		Markups:      &MarkupStorage{db: db},
 	}
 }
 
+// affectedOrNotFound turns an update or delete that matched no row into ErrNotFound
+func affectedOrNotFound(result sql.Result, record string, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%w: %s %d", ErrNotFound, record, id)
+	}
+
+	return nil
+}
+
 func withTx(ctx context.Context, db *sql.DB, f func(*sql.Tx) error) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -3,22 +3,35 @@ package store
 import (
 	"context"
 	"database/sql"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
 	"time"
 )
 
+// Conversion sides, from the customer's point of view on the base currency
+const (
+	SideMid  = "mid"
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
 type ITransaction interface {
 	Save(ctx context.Context, transaction *Transaction) error
 }
 
 type Transaction struct {
-	ID              int64     `json:"id"`
-	UserID          *int64    `json:"user_id,omitempty"`
-	BaseCode        string    `json:"base_code"`
-	TargetCode      string    `json:"target_code"`
-	ConvertedAmount float64   `json:"converted_amount"`
-	ConvertedRate   float64   `json:"converted_rate"`
-	Result          float64   `json:"result"`
-	CreatedAt       time.Time `json:"created_at"`
+	ID              int64           `json:"id"`
+	UserID          *int64          `json:"user_id,omitempty"`
+	BaseCode        string          `json:"base_code"`
+	TargetCode      string          `json:"target_code"`
+	ConvertedAmount decimal.Decimal `json:"converted_amount"`
+	// ConvertedRate is the rate applied, MidRate the rate before the markup
+	ConvertedRate decimal.Decimal `json:"converted_rate"`
+	Side          string          `json:"side"`
+	MidRate       decimal.Decimal `json:"mid_rate"`
+	// SpreadBps is how far ConvertedRate is from MidRate, in basis points
+	SpreadBps float64         `json:"spread_bps"`
+	Result    decimal.Decimal `json:"result"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type TransactionStorage struct {
@@ -28,14 +41,17 @@ type TransactionStorage struct {
 func (s *TransactionStorage) Save(ctx context.Context, transaction *Transaction) error {
 	return withTx(ctx, s.db, func(tx *sql.Tx) error {
 		query := `
-	INSERT INTO transactions(user_id, base_code, target_code, converted_amount, converted_rate, result)
-	VALUES($1, $2, $3, $4, $5, $6)`
+	INSERT INTO transactions(user_id, base_code, target_code, converted_amount, converted_rate, side, mid_rate,
+	                         spread_bps, result)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id, created_at`
 
 		ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
 		defer cancel()
 
 		args := []any{transaction.UserID, transaction.BaseCode, transaction.TargetCode, transaction.ConvertedAmount,
-			transaction.ConvertedAmount, transaction.ConvertedRate, transaction.Result}
+			transaction.ConvertedRate, transaction.Side, transaction.MidRate, transaction.SpreadBps,
+			transaction.Result}
 
 		err := tx.QueryRowContext(ctx, query, args...).Scan(&transaction.ID, &transaction.CreatedAt)
 
@@ -0,0 +1,295 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/minhnghia2k3/exchanger/internal/decimal"
+	"time"
+)
+
+type IWebhooks interface {
+	List(ctx context.Context, userID int64) ([]Webhook, error)
+	Get(ctx context.Context, userID, id int64) (*Webhook, error)
+	Create(ctx context.Context, webhook *Webhook) error
+	Update(ctx context.Context, webhook *Webhook) error
+	Delete(ctx context.Context, userID, id int64) error
+	ListActive(ctx context.Context, base, target string) ([]Webhook, error)
+	Advance(ctx context.Context, id int64, rate decimal.Decimal) error
+	LogDelivery(ctx context.Context, delivery *WebhookDelivery) error
+	Deliveries(ctx context.Context, webhookID int64, filter Filter) ([]WebhookDelivery, Metadata, error)
+	RecordOutcome(ctx context.Context, id int64, success bool, disableAfter int) (bool, error)
+}
+
+// Webhook posts rate.changed events for a pair to URL once the rate moved by
+// more than Threshold percent since the last notified rate.
+type Webhook struct {
+	ID         int64   `json:"id"`
+	UserID     int64   `json:"user_id"`
+	URL        string  `json:"url"`
+	Secret     string  `json:"-"`
+	BaseCode   string  `json:"base_code"`
+	TargetCode string  `json:"target_code"`
+	Threshold  float64 `json:"threshold"`
+	// LastRate is the rate of the last notification
+	LastRate *decimal.Decimal `json:"last_rate"`
+	Active   bool             `json:"active"`
+	// FailureCount counts the deliveries that failed in a row
+	FailureCount int        `json:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// WebhookDelivery logs one delivery attempt
+type WebhookDelivery struct {
+	ID          int64           `json:"id"`
+	WebhookID   int64           `json:"webhook_id"`
+	Event       string          `json:"event"`
+	Payload     json.RawMessage `json:"payload"`
+	Attempt     int             `json:"attempt"`
+	StatusCode  *int            `json:"status_code"`
+	Error       string          `json:"error"`
+	Success     bool            `json:"success"`
+	DurationMs  int64           `json:"duration_ms"`
+	DeliveredAt time.Time       `json:"delivered_at"`
+}
+
+type WebhookStorage struct {
+	db *sql.DB
+}
+
+const webhookColumns = `id, user_id, url, secret, base_code, target_code, threshold, last_rate, active,
+	failure_count, disabled_at, created_at`
+
+func scanWebhook(row rowScanner, webhook *Webhook) error {
+	return row.Scan(
+		&webhook.ID,
+		&webhook.UserID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.BaseCode,
+		&webhook.TargetCode,
+		&webhook.Threshold,
+		&webhook.LastRate,
+		&webhook.Active,
+		&webhook.FailureCount,
+		&webhook.DisabledAt,
+		&webhook.CreatedAt,
+	)
+}
+
+func (s *WebhookStorage) List(ctx context.Context, userID int64) ([]Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY id`
+
+	return s.list(ctx, query, userID)
+}
+
+// ListActive returns the active webhooks watching the pair
+func (s *WebhookStorage) ListActive(ctx context.Context, base, target string) ([]Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks
+	WHERE base_code = $1 AND target_code = $2 AND active
+	ORDER BY id`
+
+	return s.list(ctx, query, base, target)
+}
+
+func (s *WebhookStorage) Get(ctx context.Context, userID, id int64) (*Webhook, error) {
+	var webhook Webhook
+
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	err := scanWebhook(s.db.QueryRowContext(ctx, query, id, userID), &webhook)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, fmt.Errorf("%w: webhook %d", ErrNotFound, id)
+		default:
+			return nil, err
+		}
+	}
+
+	return &webhook, nil
+}
+
+func (s *WebhookStorage) Create(ctx context.Context, webhook *Webhook) error {
+	query := `
+	INSERT INTO webhooks(user_id, url, secret, base_code, target_code, threshold, last_rate, active)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+	RETURNING id, created_at`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{webhook.UserID, webhook.URL, webhook.Secret, webhook.BaseCode, webhook.TargetCode,
+		webhook.Threshold, webhook.LastRate, webhook.Active}
+
+	return s.db.QueryRowContext(ctx, query, args...).Scan(&webhook.ID, &webhook.CreatedAt)
+}
+
+func (s *WebhookStorage) Update(ctx context.Context, webhook *Webhook) error {
+	query := `
+	UPDATE webhooks SET url = $1, threshold = $2, active = $3, failure_count = $4, disabled_at = $5
+	WHERE id = $6 AND user_id = $7`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{webhook.URL, webhook.Threshold, webhook.Active, webhook.FailureCount, webhook.DisabledAt,
+		webhook.ID, webhook.UserID}
+
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, "webhook", webhook.ID)
+}
+
+func (s *WebhookStorage) Delete(ctx context.Context, userID, id int64) error {
+	query := `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	return affectedOrNotFound(result, "webhook", id)
+}
+
+// Advance moves the reference rate the next change is measured from
+func (s *WebhookStorage) Advance(ctx context.Context, id int64, rate decimal.Decimal) error {
+	query := `UPDATE webhooks SET last_rate = $1 WHERE id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, rate, id)
+
+	return err
+}
+
+func (s *WebhookStorage) LogDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	query := `
+	INSERT INTO webhook_deliveries(webhook_id, event, payload, attempt, status_code, error, success, duration_ms,
+	                               delivered_at)
+	VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	RETURNING id`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	args := []any{delivery.WebhookID, delivery.Event, []byte(delivery.Payload), delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.Success, delivery.DurationMs, delivery.DeliveredAt}
+
+	return s.db.QueryRowContext(ctx, query, args...).Scan(&delivery.ID)
+}
+
+// Deliveries lists the delivery attempts of a webhook, newest first
+func (s *WebhookStorage) Deliveries(ctx context.Context, webhookID int64,
+	filter Filter) ([]WebhookDelivery, Metadata, error) {
+	var deliveries []WebhookDelivery
+	var totalRecord int
+
+	query := `
+	SELECT COUNT(*) OVER(), id, webhook_id, event, payload, attempt, status_code, error, success, duration_ms,
+	       delivered_at
+	FROM webhook_deliveries
+	WHERE webhook_id = $1
+	ORDER BY delivered_at DESC, id DESC
+	LIMIT $2 OFFSET $3`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, webhookID, filter.limit(), filter.calculateOffset())
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d WebhookDelivery
+
+		err = rows.Scan(&totalRecord, &d.ID, &d.WebhookID, &d.Event, &d.Payload, &d.Attempt, &d.StatusCode,
+			&d.Error, &d.Success, &d.DurationMs, &d.DeliveredAt)
+		if err != nil {
+			return nil, Metadata{}, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, Metadata{}, err
+	}
+
+	return deliveries, filter.calculateMetadata(totalRecord), nil
+}
+
+// RecordOutcome resets the failure count after a delivery succeeded, or
+// counts the failure and disables the webhook once disableAfter deliveries
+// failed in a row. It reports whether the webhook was disabled.
+func (s *WebhookStorage) RecordOutcome(ctx context.Context, id int64, success bool,
+	disableAfter int) (bool, error) {
+	var active bool
+
+	query := `
+	UPDATE webhooks
+	SET failure_count = CASE WHEN $2 THEN 0 ELSE failure_count + 1 END,
+	    active = active AND ($2 OR failure_count + 1 < $3),
+	    disabled_at = CASE WHEN active AND NOT $2 AND failure_count + 1 >= $3 THEN NOW() ELSE disabled_at END
+	WHERE id = $1
+	RETURNING active`
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	err := s.db.QueryRowContext(ctx, query, id, success, disableAfter).Scan(&active)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return false, fmt.Errorf("%w: webhook %d", ErrNotFound, id)
+		default:
+			return false, err
+		}
+	}
+
+	return !active, nil
+}
+
+func (s *WebhookStorage) list(ctx context.Context, query string, args ...any) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	ctx, cancel := context.WithTimeout(ctx, QueryContextTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var webhook Webhook
+
+		if err = scanWebhook(rows, &webhook); err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
@@ -0,0 +1,267 @@
+// Package webhook delivers signed JSON events to subscriber URLs.
+//
+// Every request carries the event type, a unix timestamp and an HMAC-SHA256
+// signature of "<timestamp>.<body>" keyed with the subscription secret, so
+// receivers can check both the origin and the age of a payload with Verify.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	EventHeader     = "X-Exchanger-Event"
+	TimestampHeader = "X-Exchanger-Timestamp"
+	SignatureHeader = "X-Exchanger-Signature"
+)
+
+// Event types
+const (
+	EventRateChanged = "rate.changed"
+	EventTest        = "test"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrRejected is returned when the receiver answered with a status that retrying will not fix
+	ErrRejected = errors.New("webhook rejected by receiver")
+	// ErrPrivateAddress is returned for URLs that resolve to loopback, private, link-local or metadata addresses
+	ErrPrivateAddress = errors.New("webhook url must resolve to public addresses")
+)
+
+// nonPublicNets are the reserved ranges not covered by the net.IP predicates,
+// the shared address space also holds cloud metadata services
+var nonPublicNets = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),
+	mustCIDR("100.64.0.0/10"),
+	mustCIDR("192.0.0.0/24"),
+	mustCIDR("198.18.0.0/15"),
+	mustCIDR("240.0.0.0/4"),
+}
+
+func mustCIDR(s string) *net.IPNet {
+	_, n, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+type Event struct {
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Attempt describes one delivery try
+type Attempt struct {
+	Number     int
+	StatusCode int
+	Err        error
+	Duration   time.Duration
+	At         time.Time
+}
+
+func (a Attempt) Success() bool {
+	return a.Err == nil
+}
+
+type Sender struct {
+	Client *http.Client
+	// MaxAttempts includes the first try
+	MaxAttempts int
+	// Backoff is the delay before the first retry, doubled for each further retry
+	Backoff time.Duration
+}
+
+// NewSender returns a sender whose client refuses to connect to non-public
+// addresses, checked on every dial so that DNS rebinding cannot get around
+// CheckURL. allowPrivate lifts that restriction, for local development.
+func NewSender(maxAttempts int, backoff time.Duration, allowPrivate bool) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: publicOnly}
+		transport.DialContext = dialer.DialContext
+		// A proxy would be dialed instead of the receiver
+		transport.Proxy = nil
+	}
+
+	return &Sender{
+		Client:      &http.Client{Timeout: 10 * time.Second, Transport: transport},
+		MaxAttempts: max(maxAttempts, 1),
+		Backoff:     backoff,
+	}
+}
+
+// PublicIP reports whether ip is routable on the internet
+func PublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return false
+	}
+
+	for _, n := range nonPublicNets {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CheckURL resolves the host of raw and returns ErrPrivateAddress unless
+// every address it resolves to is public
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return ErrPrivateAddress
+		}
+	}
+
+	return nil
+}
+
+// publicOnly is a net.Dialer Control that refuses non-public addresses
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !PublicIP(net.ParseIP(host)) {
+		return ErrPrivateAddress
+	}
+
+	return nil
+}
+
+// Deliver posts event to url until an attempt succeeds, the receiver rejects
+// it, attempts run out or ctx is done. record, when set, sees every attempt.
+func (s *Sender) Deliver(ctx context.Context, url, secret string, event Event, record func(Attempt)) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	delay := s.Backoff
+	for n := 1; ; n++ {
+		attempt := s.attempt(ctx, url, secret, event.Type, body)
+		attempt.Number = n
+
+		if record != nil {
+			record(attempt)
+		}
+
+		if attempt.Success() || errors.Is(attempt.Err, ErrRejected) || n >= s.MaxAttempts {
+			return attempt.Err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *Sender) attempt(ctx context.Context, url, secret, eventType string, body []byte) Attempt {
+	attempt := Attempt{At: time.Now().UTC()}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		attempt.Err = err
+		return attempt
+	}
+
+	timestamp := strconv.FormatInt(attempt.At.Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	resp, err := s.Client.Do(req)
+	attempt.Duration = time.Since(attempt.At)
+	if err != nil {
+		attempt.Err = err
+		return attempt
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	attempt.StatusCode = resp.StatusCode
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooManyRequests:
+		attempt.Err = fmt.Errorf("webhook receiver answered %d", resp.StatusCode)
+	default:
+		attempt.Err = fmt.Errorf("%w: status %d", ErrRejected, resp.StatusCode)
+	}
+
+	return attempt
+}
+
+// Sign returns the signature header value for a payload sent at timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received payload against its signature and refuses
+// payloads signed more than tolerance ago, a zero tolerance skips that check.
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration) error {
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	if tolerance > 0 {
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(sent, 0)).Abs() > tolerance {
+			return ErrInvalidSignature
+		}
+	}
+
+	return nil
+}
+
+// NewSecret returns a random signing secret
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,133 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "s3cret"
+
+// receiver answers with statuses in turn, repeating the last one, and checks every signature
+func receiver(t *testing.T, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		err = Verify(secret, r.Header.Get(TimestampHeader), r.Header.Get(SignatureHeader), body, time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, EventTest, r.Header.Get(EventHeader))
+
+		n := int(calls.Add(1))
+		w.WriteHeader(statuses[min(n, len(statuses))-1])
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func testSender(srv *httptest.Server) *Sender {
+	sender := NewSender(3, time.Millisecond, true)
+	sender.Client = srv.Client()
+	return sender
+}
+
+func TestSender_Deliver(t *testing.T) {
+	event := Event{Type: EventTest, CreatedAt: time.Now(), Data: map[string]string{"hello": "world"}}
+
+	t.Run("retries until success", func(t *testing.T) {
+		srv, calls := receiver(t, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK)
+
+		var attempts []Attempt
+		err := testSender(srv).Deliver(context.Background(), srv.URL, secret, event, func(a Attempt) {
+			attempts = append(attempts, a)
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Len(t, attempts, 3)
+		assert.Equal(t, 3, attempts[2].Number)
+		assert.Equal(t, http.StatusOK, attempts[2].StatusCode)
+		assert.False(t, attempts[0].Success())
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		srv, calls := receiver(t, http.StatusInternalServerError)
+
+		err := testSender(srv).Deliver(context.Background(), srv.URL, secret, event, nil)
+
+		assert.Error(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("does not retry a rejection", func(t *testing.T) {
+		srv, calls := receiver(t, http.StatusGone)
+
+		err := testSender(srv).Deliver(context.Background(), srv.URL, secret, event, nil)
+
+		assert.ErrorIs(t, err, ErrRejected)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"type":"test"}`)
+	ts := "1700000000"
+	signature := Sign(secret, ts, body)
+
+	assert.NoError(t, Verify(secret, ts, signature, body, 0))
+	assert.ErrorIs(t, Verify("other", ts, signature, body, 0), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(secret, ts, signature, []byte(`{"type":"forged"}`), 0), ErrInvalidSignature)
+	// Signed long ago
+	assert.ErrorIs(t, Verify(secret, ts, signature, body, time.Minute), ErrInvalidSignature)
+}
+
+func TestSender_PrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a loopback receiver must not be reached")
+	}))
+	t.Cleanup(srv.Close)
+
+	event := Event{Type: EventTest, CreatedAt: time.Now()}
+
+	err := NewSender(1, time.Millisecond, false).Deliver(context.Background(), srv.URL, secret, event, nil)
+
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"::1":              false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"100.100.100.200":  false,
+		"fd00:ec2::254":    false,
+		"fe80::1":          false,
+		"0.0.0.0":          false,
+		"::ffff:127.0.0.1": false,
+	}
+
+	for ip, public := range tests {
+		assert.Equal(t, public, PublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	assert.ErrorIs(t, CheckURL(context.Background(), "https://127.0.0.1:8080/hook"), ErrPrivateAddress)
+	assert.ErrorIs(t, CheckURL(context.Background(), "https://[::1]/hook"), ErrPrivateAddress)
+	assert.ErrorIs(t, CheckURL(context.Background(), "https://localhost/hook"), ErrPrivateAddress)
+}